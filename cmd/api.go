@@ -0,0 +1,91 @@
+// cmd/api.go
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"ai-content-gen/internal/api"
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/jobs"
+	"ai-content-gen/internal/pipeline"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/pkg/utils"
+)
+
+const (
+	defaultAPIAddr  = ":8090"
+	shutdownTimeout = 10 * time.Second
+)
+
+// runAPI выполняет команду api: HTTP-сервер заданий на генерацию роликов до сигнала остановки.
+func runAPI(args []string, cfg *config.Config, logger *utils.Logger) error {
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	addr := fs.String("addr", cfg.App.API.Addr, "адрес HTTP-сервера")
+	workers := fs.Int("workers", cfg.App.API.Workers, "обработчиков заданий в процессе API; 0 — только отдельные процессы worker")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *addr == "" {
+		*addr = defaultAPIAddr
+	}
+	if cfg.APIToken == "" {
+		logger.Warn("API_TOKEN не установлен, API доступно без токена")
+	}
+
+	// Очередь заданий хранится в базе истории, без нее API не работает
+	history, err := store.Open(storePath(cfg), logger)
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           api.NewServer(jobs.NewQueue(cfg, history, logger), cfg.APIToken, logger).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Задания выполняют обработчики в этом же процессе (api.workers) и/или отдельные процессы worker
+	workersDone := make(chan struct{})
+	go func() {
+		defer close(workersDone)
+		if *workers > 0 {
+			jobsCfg := cfg.App.Jobs
+			jobsCfg.Workers = *workers
+			jobs.NewWorker(pipeline.New(cfg, history, logger), history, jobsCfg, logger).Run(ctx)
+		}
+	}()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("HTTP API слушает %s", *addr)
+		serveErr <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-serveErr:
+		stop()
+		<-workersDone
+		return err
+	case <-ctx.Done():
+	}
+
+	// Повторный сигнал завершит процесс сразу, не дожидаясь текущих заданий
+	stop()
+	logger.Info("Получен сигнал остановки, ждем завершения текущих заданий...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Warn("HTTP-сервер остановлен с ошибкой: %v", err)
+	}
+	<-workersDone
+	return nil
+}
@@ -0,0 +1,145 @@
+// cmd/history.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/pkg/utils"
+)
+
+const historyUsage = `Использование:
+  history list [-channel имя] [-limit N]           последние запуски
+  history search [-channel имя] [-limit N] текст   поиск по теме, идее и сценарию
+  history show <run id>                            подробности запуска`
+
+// runHistory выполняет команду history: просмотр и поиск прошлых запусков в базе истории.
+func runHistory(args []string, cfg *config.Config, logger *utils.Logger) error {
+	if len(args) == 0 {
+		return fmt.Errorf("не указана подкоманда\n%s", historyUsage)
+	}
+
+	db, err := store.Open(storePath(cfg), logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	subcommand, args := args[0], args[1:]
+	switch subcommand {
+	case "list", "search":
+		fs := flag.NewFlagSet("history "+subcommand, flag.ContinueOnError)
+		channel := fs.String("channel", "", "только запуски канала")
+		limit := fs.Int("limit", 20, "сколько запусков показать")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		filter := store.RunFilter{Channel: *channel, Limit: *limit}
+		if subcommand == "search" {
+			filter.Query = strings.Join(fs.Args(), " ")
+			if filter.Query == "" {
+				return fmt.Errorf("не указан текст для поиска\n%s", historyUsage)
+			}
+		}
+		runs, err := db.ListRuns(filter)
+		if err != nil {
+			return err
+		}
+		printRuns(runs)
+	case "show":
+		if len(args) != 1 {
+			return fmt.Errorf("укажите идентификатор запуска\n%s", historyUsage)
+		}
+		details, err := db.GetRun(args[0])
+		if err != nil {
+			return err
+		}
+		printRunDetails(details)
+	default:
+		return fmt.Errorf("неизвестная подкоманда %q\n%s", subcommand, historyUsage)
+	}
+	return nil
+}
+
+// storePath возвращает путь к базе истории из конфигурации.
+func storePath(cfg *config.Config) string {
+	if cfg.App.Store.Path != "" {
+		return cfg.App.Store.Path
+	}
+	return "data/history.db"
+}
+
+func printRuns(runs []store.Run) {
+	if len(runs) == 0 {
+		fmt.Println("Запусков не найдено.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN ID\tНАЧАЛО\tКАНАЛ\tСТАТУС\tИДЕЯ")
+	for _, run := range runs {
+		idea := run.Idea
+		if idea == "" {
+			idea = run.Topic
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", run.ID, run.StartedAt.Format("2006-01-02 15:04"), run.Channel, run.Status, truncate(idea, 60))
+	}
+	w.Flush()
+}
+
+func printRunDetails(details *store.RunDetails) {
+	fmt.Printf("Запуск:     %s\n", details.ID)
+	fmt.Printf("Канал:      %s\n", details.Channel)
+	fmt.Printf("Тема:       %s\n", details.Topic)
+	fmt.Printf("Статус:     %s\n", details.Status)
+	if details.Error != "" {
+		fmt.Printf("Ошибка:     %s\n", details.Error)
+	}
+	fmt.Printf("Начало:     %s\n", details.StartedAt.Format(time.DateTime))
+	if !details.FinishedAt.IsZero() {
+		fmt.Printf("Завершение: %s (%s)\n", details.FinishedAt.Format(time.DateTime), details.FinishedAt.Sub(details.StartedAt).Round(time.Second))
+	}
+	fmt.Printf("Директория: %s\n", details.Workspace)
+
+	if details.Script != nil {
+		fmt.Printf("\nИдея: %s\n", details.Script.Idea)
+		if details.Script.Hook != "" {
+			fmt.Printf("Хук: %s\n", details.Script.Hook)
+		}
+		if details.Script.CallToAction != "" {
+			fmt.Printf("Призыв: %s\n", details.Script.CallToAction)
+		}
+		fmt.Printf("\n%s\n", strings.TrimSpace(details.Script.Content))
+	}
+
+	if len(details.Renders) > 0 {
+		fmt.Println("\nРолики:")
+		for _, render := range details.Renders {
+			fmt.Printf("  %-10s %s (%.1f МБ)\n", render.Name, render.Path, float64(render.Size)/(1<<20))
+		}
+	}
+	if len(details.Publications) > 0 {
+		fmt.Println("\nПубликации:")
+		for _, publication := range details.Publications {
+			if publication.Error != "" {
+				fmt.Printf("  %-8s ошибка: %s\n", publication.Platform, publication.Error)
+			} else {
+				fmt.Printf("  %-8s %s (%s)\n", publication.Platform, publication.URL, publication.PublishedAt.Format(time.DateTime))
+			}
+		}
+	}
+}
+
+// truncate обрезает строку до n символов.
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,181 @@
+// cmd/jobs.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/jobs"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/internal/workspace"
+	"ai-content-gen/pkg/utils"
+)
+
+const jobsUsage = `Использование:
+  jobs list [-status dead] [-limit N]   задания очереди, новые первыми
+  jobs show <id>                        задание, метаданные публикации и решение проверяющего
+  jobs cancel <id>                      отменить задание
+  jobs retry <id> [-stage этап]         повторить отмененное, отклоненное или исчерпавшее попытки задание (по умолчанию с упавшего этапа)
+  jobs approve <id> [-comment текст]    одобрить ролик, ждущий проверки, к публикации по расписанию
+  jobs publish <id> [-comment текст]    одобрить ролик, ждущий проверки, и опубликовать сразу
+  jobs reject <id> -comment текст       отклонить ролик, ждущий проверки
+  jobs edit <id> -platform youtube [-title ...] [-description ...] [-tags ...]
+                                        изменить метаданные ролика до одобрения
+  jobs regenerate <id> -scenes 2,4      заново сгенерировать сцены и пересобрать ролик`
+
+// runJobs выполняет команду jobs: просмотр очереди заданий, проверка роликов и разбор заданий в dead.
+func runJobs(args []string, cfg *config.Config, logger *utils.Logger) error {
+	if len(args) == 0 {
+		return fmt.Errorf("не указана подкоманда\n%s", jobsUsage)
+	}
+
+	db, err := store.Open(storePath(cfg), logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	queue := jobs.NewQueue(cfg, db, logger)
+
+	subcommand, args := args[0], args[1:]
+	fs := flag.NewFlagSet("jobs "+subcommand, flag.ContinueOnError)
+	status := fs.String("status", "", "только задания со статусом (queued, running, awaiting_review, completed, rejected, canceled, dead)")
+	limit := fs.Int("limit", 50, "сколько заданий показать")
+	stage := fs.String("stage", "", "этап, с которого повторить запуск")
+	reviewer := fs.String("reviewer", os.Getenv("USER"), "имя проверяющего")
+	comment := fs.String("comment", "", "комментарий проверяющего")
+	platform := fs.String("platform", "", "платформа, метаданные которой изменяются")
+	title := fs.String("title", "", "новый заголовок")
+	description := fs.String("description", "", "новое описание")
+	tags := fs.String("tags", "", "новые теги через запятую")
+	scenes := fs.String("scenes", "", "номера сцен через запятую, начиная с 1")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if subcommand == "list" {
+		list, err := queue.List(*status, *limit)
+		if err != nil {
+			return err
+		}
+		printJobs(list)
+		return nil
+	}
+
+	// id может стоять как до, так и после флагов
+	id := fs.Arg(0)
+	if id == "" {
+		return fmt.Errorf("укажите id задания\n%s", jobsUsage)
+	}
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return err
+	}
+	switch subcommand {
+	case "show":
+		job, err := queue.Get(id)
+		if err != nil {
+			return err
+		}
+		manifest, err := queue.Manifest(job)
+		if err != nil {
+			return err
+		}
+		printJob(job, manifest)
+	case "cancel":
+		_, err = queue.Cancel(id)
+	case "retry":
+		_, err = queue.Retry(id, *stage)
+	case "approve":
+		_, err = queue.Approve(id, *reviewer, *comment)
+	case "publish":
+		_, err = queue.Publish(id, *reviewer, *comment)
+	case "reject":
+		if *comment == "" {
+			return fmt.Errorf("укажите причину отклонения в -comment")
+		}
+		_, err = queue.Reject(id, *reviewer, *comment)
+	case "edit":
+		if *platform == "" {
+			return fmt.Errorf("укажите платформу в -platform")
+		}
+		_, err = queue.EditMetadata(id, []workspace.PublishMetadata{{Platform: *platform, Title: *title, Description: *description, Tags: *tags}})
+	case "regenerate":
+		list, err := parseScenes(*scenes)
+		if err != nil {
+			return err
+		}
+		_, err = queue.Regenerate(id, list)
+		return err
+	default:
+		return fmt.Errorf("неизвестная подкоманда %q\n%s", subcommand, jobsUsage)
+	}
+	return err
+}
+
+// parseScenes разбирает номера сцен через запятую.
+func parseScenes(value string) ([]int, error) {
+	var scenes []int
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		scene, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный номер сцены %q", field)
+		}
+		scenes = append(scenes, scene)
+	}
+	if len(scenes) == 0 {
+		return nil, fmt.Errorf("укажите сцены в -scenes, например -scenes 2,4")
+	}
+	return scenes, nil
+}
+
+func printJobs(list []store.Job) {
+	if len(list) == 0 {
+		fmt.Println("Заданий не найдено.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tСОЗДАНО\tКАНАЛ\tСТАТУС\tЭТАП\tПОПЫТКИ\tЗАПУСК\tОШИБКА")
+	for _, job := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d/%d\t%s\t%s\n", job.ID, job.CreatedAt.Format("2006-01-02 15:04"), job.Channel, job.Status,
+			job.Stage, job.Attempts, job.MaxAttempts, job.RunID, truncate(job.Error, 60))
+	}
+	w.Flush()
+}
+
+// printJob выводит задание вместе с метаданными публикации и решением проверяющего из манифеста.
+func printJob(job *store.Job, manifest *workspace.Manifest) {
+	fmt.Printf("Задание:  %s\n", job.ID)
+	fmt.Printf("Статус:   %s\n", job.Status)
+	fmt.Printf("Канал:    %s\n", job.Channel)
+	fmt.Printf("Идея:     %s\n", job.Idea)
+	if job.VideoPath != "" {
+		fmt.Printf("Ролик:    %s\n", job.VideoPath)
+	}
+	if job.Error != "" {
+		fmt.Printf("Ошибка:   %s\n", job.Error)
+	}
+	if manifest == nil {
+		return
+	}
+	if review := manifest.Review; review != nil {
+		fmt.Printf("Проверка: %s", review.Status)
+		if review.Reviewer != "" {
+			fmt.Printf(" (%s, %s)", review.Reviewer, review.UpdatedAt.Format("2006-01-02 15:04"))
+		}
+		if review.Comment != "" {
+			fmt.Printf(": %s", review.Comment)
+		}
+		fmt.Println()
+	}
+	for _, md := range manifest.Metadata {
+		fmt.Printf("\n[%s]\nЗаголовок: %s\nТеги:      %s\nОписание:\n%s\n", md.Platform, md.Title, md.Tags, md.Description)
+	}
+}
@@ -2,19 +2,25 @@
 package main
 
 import (
-	"fmt"
-	"os"
-	"regexp"
-	"strings"
+	"context"
+	"errors"
+	"flag"
+	"time"
 
-	"ai-content-gen/internal/ai"
 	"ai-content-gen/internal/config"
-	"ai-content-gen/internal/uploader"
-	"ai-content-gen/internal/video"
+	"ai-content-gen/internal/jobs"
+	"ai-content-gen/internal/pipeline"
+	"ai-content-gen/internal/store"
 	"ai-content-gen/pkg/utils"
 )
 
 func main() {
+	channelName := flag.String("channel", "", "имя канала из config.yaml (оформление и публикация)")
+	noCache := flag.Bool("no-cache", false, "не брать ответы моделей из кэша и не сохранять их")
+	topicFlag := flag.String("topic", "", "тема ролика; по умолчанию берется из очереди тем канала")
+	publishAtFlag := flag.String("publish-at", "", "время выхода ролика (2006-01-02 15:04 или RFC 3339); по умолчанию по platforms.*.publish_at или сразу")
+	flag.Parse()
+
 	// Инициализируем логгер первым делом
 	logger := utils.NewLogger()
 	logger.Info("Запуск YouTube Shorts AI Bot...")
@@ -24,185 +30,62 @@ func main() {
 	if err != nil {
 		logger.Fatal("Ошибка загрузки конфигурации: %v", err)
 	}
-
-	logger.Info("Бот запущен с настройкой: %s", cfg.AppName)
-	logger.Info("Эндпоинт текстового ИИ: %s", cfg.TextAIEndpoint)
-	logger.Info("Эндпоинт видео ИИ: %s", cfg.VideoAIEndpoint)
-	logger.Info("Модель текстового ИИ: %s", cfg.App.AI.Text.Model)
-
-	// Инициализация сервисов
-	textGen := ai.NewTextGenerator(cfg.TextAIEndpoint, cfg.App, logger)
-	videoGen := ai.NewVideoGenerator(cfg.VideoAIEndpoint, cfg.VideoAIAPIKey, cfg.App, logger)
-	videoEditor := video.NewVideoEditor(logger)
-
-	// Инициализация мультиплатформенного загрузчика с ключами из конфига
-	multiUploader := uploader.NewMultiPlatformUploader(
-		cfg.YouTubeAPIKey,
-		cfg.TikTokAPIKey,
-		logger,
-	)
-
-	topic := "космическая битва с флотом Федерации"
-
-	// 1. Генерируем общую идею и краткое описание сцен
-	generalContent, err := textGen.GenerateShortsIdeaAndScenes(topic)
-	if err != nil {
-		logger.Fatal("Ошибка при генерации общей идеи и сцен: %v", err)
+	if *noCache {
+		cfg.App.Cache.Enabled = false
 	}
 
-	logger.Info("\n--- Сгенерированная общая идея и сцены ---")
-	fmt.Println(generalContent)
-	logger.Info("----------------------------------------")
-
-	overallIdea, sceneDescriptions := parseIdeaAndScenes(generalContent, logger)
-	if overallIdea == "" || len(sceneDescriptions) == 0 {
-		logger.Fatal("Не удалось извлечь идею или описания сцен из сгенерированного контента.")
+	// Подкоманды: просмотр истории, очередь тем, режим демона, HTTP API и очередь заданий
+	commands := map[string]func([]string, *config.Config, *utils.Logger) error{
+		"history": runHistory,
+		"topics":  runTopics,
+		"publish": runPublish,
+		"serve":   runServe,
+		"daemon":  runServe,
+		"api":     runAPI,
+		"worker":  runWorker,
+		"jobs":    runJobs,
 	}
-
-	logger.Info("Общая идея: %s", overallIdea)
-	for i, scene := range sceneDescriptions {
-		logger.Info("Сцена %d: %s", i+1, scene)
-	}
-
-	// Создаем директорию для временных видео
-	if err := os.MkdirAll("temp_videos", os.ModePerm); err != nil {
-		logger.Fatal("Не удалось создать директорию temp_videos: %v", err)
-	}
-	defer func() { // Добавляем defer для очистки временных файлов
-		logger.Info("Очистка временных видеофайлов...")
-		if err := os.RemoveAll("temp_videos"); err != nil {
-			logger.Warn("Не удалось удалить временную папку 'temp_videos': %v", err)
-		}
-		logger.Info("Временные видеофайлы удалены.")
-	}()
-
-	// 2. Для каждой сцены генерируем подробный промпт для видео
-	logger.Info("\n--- Генерация подробных промптов для видео ---")
-	var detailedPrompts []string
-	for i, sceneDesc := range sceneDescriptions {
-		detailedPrompt, err := textGen.GenerateVideoPromptForScene(overallIdea, sceneDesc)
-		if err != nil {
-			logger.Error("Ошибка при генерации подробного промпта для сцены %d: %v", i+1, err)
-			continue
+	if command, ok := commands[flag.Arg(0)]; ok {
+		if err := command(flag.Args()[1:], cfg, logger); err != nil {
+			logger.Fatal("%v", err)
 		}
-		detailedPrompts = append(detailedPrompts, detailedPrompt)
-		logger.Info("Детальный промпт для Сцены %d:\n%s\n", i+1, detailedPrompt)
-		logger.Info("-------------------------------------------")
+		return
 	}
 
-	if len(detailedPrompts) == 0 {
-		logger.Fatal("Не удалось сгенерировать ни одного детального промпта.")
+	if cfg.App.Cache.Enabled {
+		logger.Info("Кэш ответов моделей: %s", cfg.App.Cache.Dir)
 	}
+	logger.Info("Бот запущен с настройкой: %s", cfg.AppName)
+	logger.Info("Эндпоинт текстового ИИ: %s", cfg.TextAIEndpoint)
+	logger.Info("Эндпоинт видео ИИ: %s", cfg.VideoAIEndpoint)
+	logger.Info("Модель текстового ИИ: %s", cfg.App.AI.Text.Model)
 
-	// 3. Генерируем все видеосегменты на основе детальных промптов
-	logger.Info("\n--- Генерация всех видеосегментов ---")
-	var videoSegmentPaths []string
-	for i, prompt := range detailedPrompts {
-		segmentPath, err := videoGen.GenerateVideoSegment(prompt, i+1)
-		if err != nil {
-			logger.Error("Ошибка при генерации видео для сцены %d: %v", i+1, err)
-			continue
+	var publishAt time.Time
+	if *publishAtFlag != "" {
+		if publishAt, err = parsePublishTime(*publishAtFlag); err != nil {
+			logger.Fatal("%v", err)
 		}
-		videoSegmentPaths = append(videoSegmentPaths, segmentPath)
-		logger.Info("Видеофрагмент для Сцены %d сгенерирован и сохранен: %s", i+1, segmentPath)
-		logger.Info("-------------------------------------------")
-	}
-
-	if len(videoSegmentPaths) == 0 {
-		logger.Fatal("Не удалось сгенерировать ни одного видеофрагмента.")
-	}
-
-	// 4. Склеиваем видеосегменты в одно финальное видео
-	outputDir := "output_shorts"
-	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
-		logger.Fatal("Не удалось создать директорию %s: %v", outputDir, err)
 	}
-	finalVideoPath := fmt.Sprintf("%s/%s_final_short.%s", outputDir, strings.ReplaceAll(overallIdea, " ", "_"), cfg.App.AI.Video.OutputFormat)
 
-	compiledVideoPath, err := videoEditor.ConcatenateVideos(videoSegmentPaths, finalVideoPath, cfg.App.AI.Video.FPS)
+	// История запусков: по ней видно, какие темы и ролики уже были на канале
+	history, err := store.Open(storePath(cfg), logger)
 	if err != nil {
-		logger.Fatal("Ошибка при склейке видео: %v", err)
-	}
-	logger.Info("Финальное видео скомпилировано: %s", compiledVideoPath)
-
-	// Очистка временных видеофайлов после склейки
-	logger.Info("Удаление отдельных видеосегментов после склейки...")
-	for _, path := range videoSegmentPaths {
-		if err := os.Remove(path); err != nil {
-			logger.Warn("Не удалось удалить временный файл %s: %v", path, err)
+		// Ролик на проверке ждет одобрения в очереди заданий, а она хранится в базе истории
+		if cfg.App.Review.Enabled {
+			logger.Fatal("База истории недоступна, а без нее ролик нельзя отправить на проверку: %v", err)
 		}
+		logger.Warn("База истории недоступна, запуск не будет в нее записан: %v", err)
+		history = nil
 	}
-	logger.Info("Отдельные видеосегменты удалены.")
-
-	// 5. Отправляем это ОДНО финальное видео на ВСЕ нужные платформы
-	logger.Info("\n--- Загрузка финального видео на платформы ---")
 
-	// Метаданные для YouTube
-	youtubeTitle := fmt.Sprintf("AI Shorts: %s", overallIdea)
-	youtubeDescription := fmt.Sprintf("Это YouTube Shorts, сгенерированный полностью AI на тему: %s.", overallIdea)
-	youtubeTags := "AI,Shorts,YouTubeShorts,AIgenerated,космическаябитва,федерация"
-
-	// Загрузка на YouTube
-	ytVideoURL, err := multiUploader.Upload(uploader.PlatformYouTube, compiledVideoPath, youtubeTitle, youtubeDescription, youtubeTags)
-	if err != nil {
-		logger.Error("Ошибка при загрузке видео на YouTube: %v", err) // Не фатально, если хотим попробовать другие платформы
-	} else {
-		logger.Info("Видео успешно загружено на YouTube: %s", ytVideoURL)
+	req := pipeline.Request{Channel: *channelName, Topic: *topicFlag, PublishAt: publishAt, Review: cfg.App.Review.Enabled}
+	result, err := pipeline.New(cfg, history, logger).Run(context.Background(), req)
+	if errors.Is(err, pipeline.ErrAwaitingReview) {
+		_, err = jobs.NewQueue(cfg, history, logger).AwaitReview(req, result)
 	}
-
-	// Метаданные для TikTok
-	tiktokTitle := fmt.Sprintf("AI Космос: %s", overallIdea)
-	tiktokDescription := "Генерация AI для TikTok! #AI #Shorts"
-	tiktokTags := "AI,космос,shorts"
-
-	// Загрузка на TikTok (пример)
-	tiktokVideoURL, err := multiUploader.Upload(uploader.PlatformTikTok, compiledVideoPath, tiktokTitle, tiktokDescription, tiktokTags)
+	history.Close()
 	if err != nil {
-		logger.Error("Ошибка при загрузке видео на TikTok: %v", err)
-	} else {
-		logger.Info("Видео успешно загружено на TikTok: %s", tiktokVideoURL)
+		logger.Fatal("%v", err)
 	}
-
 	logger.Info("Бот завершил свою работу!")
 }
-
-// parseIdeaAndScenes разбирает сгенерированный текст на общую идею и отдельные описания сцен.
-func parseIdeaAndScenes(content string, logger *utils.Logger) (string, []string) {
-	var overallIdea string
-	var scenes []string
-
-	lines := strings.Split(content, "\n")
-	sceneRegex := regexp.MustCompile(`^Сцена \d+: (.+)`)
-	ideaRegex := regexp.MustCompile(`^Идея: (.+)`)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		if strings.HasPrefix(line, "Идея:") {
-			if matches := ideaRegex.FindStringSubmatch(line); len(matches) > 1 {
-				overallIdea = matches[1]
-				logger.Info("Извлечена идея: %s", overallIdea)
-			}
-		} else if matches := sceneRegex.FindStringSubmatch(line); len(matches) > 1 {
-			// Добавляем проверку на наличие содержимого после "Сцена N: "
-			if len(matches[1]) > 0 {
-				scenes = append(scenes, matches[1])
-				logger.Info("Извлечена сцена: %s", matches[1])
-			} else {
-				logger.Warn("Пустое описание для сцены в строке: %s", line)
-			}
-		}
-	}
-
-	if overallIdea == "" {
-		logger.Warn("Не удалось найти 'Идея:' в сгенерированном контенте.")
-	}
-	if len(scenes) == 0 {
-		logger.Warn("Не удалось найти ни одной 'Сцены' в сгенерированном контенте.")
-	}
-
-	return overallIdea, scenes
-}
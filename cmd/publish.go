@@ -0,0 +1,114 @@
+// cmd/publish.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/pipeline"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/pkg/utils"
+)
+
+const publishUsage = `Использование:
+  publish list [-status pending] [-limit N]   очередь отложенных публикаций
+  publish release                             опубликовать все, чье время наступило (для запуска из cron без демона)
+  publish cancel <id>                         отменить ожидающую публикацию
+  publish retry <id> [-at время]              повторить неудавшуюся публикацию (по умолчанию сразу)`
+
+// runPublish выполняет команду publish: управление локальной очередью отложенных публикаций.
+func runPublish(args []string, cfg *config.Config, logger *utils.Logger) error {
+	if len(args) == 0 {
+		return fmt.Errorf("не указана подкоманда\n%s", publishUsage)
+	}
+
+	db, err := store.Open(storePath(cfg), logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	subcommand, args := args[0], args[1:]
+	fs := flag.NewFlagSet("publish "+subcommand, flag.ContinueOnError)
+	status := fs.String("status", "", "только публикации со статусом (pending, published, failed, canceled)")
+	limit := fs.Int("limit", 50, "сколько публикаций показать")
+	at := fs.String("at", "", "время публикации: 2006-01-02 15:04 или RFC 3339")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch subcommand {
+	case "list":
+		publications, err := db.ListPublications(*status, *limit)
+		if err != nil {
+			return err
+		}
+		printPublications(publications)
+	case "release":
+		released, err := pipeline.New(cfg, db, logger).ReleaseDue(time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Опубликовано из очереди: %d\n", released)
+	case "cancel", "retry":
+		// id может стоять как до, так и после флагов
+		idArg := fs.Arg(0)
+		if idArg == "" {
+			return fmt.Errorf("укажите id публикации\n%s", publishUsage)
+		}
+		id, err := strconv.ParseInt(idArg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("некорректный id публикации %q", idArg)
+		}
+		if subcommand == "cancel" {
+			return db.CancelPublication(id)
+		}
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return err
+		}
+		publishAt := time.Now()
+		if *at != "" {
+			if publishAt, err = parsePublishTime(*at); err != nil {
+				return err
+			}
+		}
+		return db.RetryPublication(id, publishAt)
+	default:
+		return fmt.Errorf("неизвестная подкоманда %q\n%s", subcommand, publishUsage)
+	}
+	return nil
+}
+
+// parsePublishTime разбирает время публикации в формате RFC 3339 или "2006-01-02 15:04" (локальное время).
+func parsePublishTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("некорректное время публикации %q: ожидается 2006-01-02 15:04 или RFC 3339", value)
+	}
+	return t, nil
+}
+
+func printPublications(publications []store.ScheduledPublication) {
+	if len(publications) == 0 {
+		fmt.Println("Публикаций не найдено.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tВРЕМЯ\tПЛАТФОРМА\tСТАТУС\tЗАПУСК\tРЕЗУЛЬТАТ")
+	for _, p := range publications {
+		result := p.URL
+		if p.Error != "" {
+			result = truncate(p.Error, 60)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", p.ID, p.PublishAt.Format("2006-01-02 15:04"), p.Platform, p.Status, p.RunID, result)
+	}
+	w.Flush()
+}
@@ -0,0 +1,74 @@
+// cmd/serve.go
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/jobs"
+	"ai-content-gen/internal/pipeline"
+	"ai-content-gen/internal/scheduler"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/internal/topics"
+	"ai-content-gen/pkg/utils"
+)
+
+// runServe выполняет команду serve (daemon): запуски по расписаниям каналов до сигнала остановки.
+func runServe(args []string, cfg *config.Config, logger *utils.Logger) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	channels := fs.String("channel", "", "каналы через запятую; по умолчанию все каналы с расписанием")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Без базы истории демон не помнит выполненные слоты и может опубликовать ролик дважды
+	history, err := store.Open(storePath(cfg), logger)
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+
+	textGen := ai.NewTextGenerator(cfg.TextAIEndpoint, cfg.App, logger)
+	daemon := scheduler.NewDaemon(cfg, pipeline.New(cfg, history, logger), topics.NewCollector(textGen, history, logger), history, logger)
+	for _, name := range strings.Split(*channels, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			daemon.Channels = append(daemon.Channels, name)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Повторный сигнал завершит процесс сразу, не дожидаясь текущего запуска
+			stop()
+			logger.Info("Получен сигнал остановки, ждем завершения текущего запуска...")
+		case <-done:
+		}
+	}()
+
+	// Одобренные проверяющим ролики публикует обработчик очереди, поэтому при проверке он работает и в демоне
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	workersDone := make(chan struct{})
+	go func() {
+		defer close(workersDone)
+		if cfg.App.Review.Enabled {
+			jobs.NewWorker(daemon.Pipeline, history, cfg.App.Jobs, logger).Run(workersCtx)
+		}
+	}()
+
+	logger.Info("Демон запущен")
+	err = daemon.Run(ctx)
+	stopWorkers()
+	<-workersDone
+	return err
+}
@@ -0,0 +1,107 @@
+// cmd/topics.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/internal/topics"
+	"ai-content-gen/pkg/utils"
+)
+
+const topicsUsage = `Использование:
+  topics fetch [-channel имя]                              собрать, оценить и поставить в очередь темы из источников
+  topics list [-channel имя] [-status queued] [-limit N]   очередь тем
+  topics add [-channel имя] тема                           добавить тему в очередь вручную
+  topics reject <id>                                       убрать тему из очереди`
+
+// runTopics выполняет команду topics: сбор тем из лент и списков и управление очередью тем канала.
+func runTopics(args []string, cfg *config.Config, logger *utils.Logger) error {
+	if len(args) == 0 {
+		return fmt.Errorf("не указана подкоманда\n%s", topicsUsage)
+	}
+
+	db, err := store.Open(storePath(cfg), logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	subcommand, args := args[0], args[1:]
+	fs := flag.NewFlagSet("topics "+subcommand, flag.ContinueOnError)
+	channelName := fs.String("channel", "", "имя канала из config.yaml")
+	status := fs.String("status", "", "только темы со статусом (queued, used, rejected)")
+	limit := fs.Int("limit", 50, "сколько тем показать")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch subcommand {
+	case "fetch":
+		channel, err := cfg.App.Channel(*channelName)
+		if err != nil {
+			return err
+		}
+		textGen := ai.NewTextGenerator(cfg.TextAIEndpoint, cfg.App, logger)
+		result, err := topics.NewCollector(textGen, db, logger).Collect(channel)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Получено кандидатов: %d, новых оценено: %d, поставлено в очередь: %d\n", result.Fetched, result.Scored, len(result.Queued))
+	case "list":
+		list, err := db.ListTopics(*channelName, *status, *limit)
+		if err != nil {
+			return err
+		}
+		printTopics(list)
+	case "add":
+		channel, err := cfg.App.Channel(*channelName)
+		if err != nil {
+			return err
+		}
+		topic := strings.TrimSpace(strings.Join(fs.Args(), " "))
+		if topic == "" {
+			return fmt.Errorf("не указана тема\n%s", topicsUsage)
+		}
+		inserted, err := db.EnqueueTopic(store.QueuedTopic{Channel: channel.Name, Topic: topic, Source: "manual", Score: 10})
+		if err != nil {
+			return err
+		}
+		if !inserted {
+			return fmt.Errorf("тема уже есть у канала %s", channel.Name)
+		}
+		fmt.Printf("Тема добавлена в очередь канала %s\n", channel.Name)
+	case "reject":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("укажите id темы\n%s", topicsUsage)
+		}
+		id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("некорректный id темы %q", fs.Arg(0))
+		}
+		return db.SetTopicStatus(id, store.TopicRejected)
+	default:
+		return fmt.Errorf("неизвестная подкоманда %q\n%s", subcommand, topicsUsage)
+	}
+	return nil
+}
+
+func printTopics(list []store.QueuedTopic) {
+	if len(list) == 0 {
+		fmt.Println("Тем не найдено.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tКАНАЛ\tСТАТУС\tОЦЕНКА\tИСТОЧНИК\tТЕМА")
+	for _, topic := range list {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%.1f\t%s\t%s\n", topic.ID, topic.Channel, topic.Status, topic.Score, topic.Source, truncate(topic.Topic, 70))
+	}
+	w.Flush()
+}
@@ -0,0 +1,53 @@
+// cmd/worker.go
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/jobs"
+	"ai-content-gen/internal/pipeline"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/pkg/utils"
+)
+
+// runWorker выполняет команду worker: задания из очереди до сигнала остановки.
+// Процессов worker может быть несколько, в том числе на разных машинах с общим диском.
+func runWorker(args []string, cfg *config.Config, logger *utils.Logger) error {
+	fs := flag.NewFlagSet("worker", flag.ContinueOnError)
+	workers := fs.Int("workers", cfg.App.Jobs.Workers, "сколько заданий выполнять одновременно")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	history, err := store.Open(storePath(cfg), logger)
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+
+	jobsCfg := cfg.App.Jobs
+	jobsCfg.Workers = *workers
+	worker := jobs.NewWorker(pipeline.New(cfg, history, logger), history, jobsCfg, logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Повторный сигнал завершит процесс сразу; аренда его заданий истечет, и их возьмет другой обработчик
+			stop()
+			logger.Info("Получен сигнал остановки, ждем завершения текущих заданий...")
+		case <-done:
+		}
+	}()
+
+	worker.Run(ctx)
+	return nil
+}
@@ -0,0 +1,107 @@
+// internal/ai/embeddings.go
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+// EmbeddingGenerator получает векторные представления текста
+// (OpenAI-совместимый эндпоинт /v1/embeddings).
+type EmbeddingGenerator struct {
+	Endpoint string
+	APIKey   string
+	Config   *config.AppConfig
+	Client   *http.Client
+	Logger   *utils.Logger
+}
+
+// NewEmbeddingGenerator создает новый экземпляр EmbeddingGenerator.
+func NewEmbeddingGenerator(endpoint, apiKey string, cfg *config.AppConfig, logger *utils.Logger) *EmbeddingGenerator {
+	return &EmbeddingGenerator{
+		Endpoint: endpoint,
+		APIKey:   apiKey,
+		Config:   cfg,
+		Client:   &http.Client{Timeout: 60 * time.Second},
+		Logger:   logger,
+	}
+}
+
+// EmbeddingRequest соответствует структуре запроса к эндпоинту эмбеддингов.
+type EmbeddingRequest struct {
+	Model string `json:"model,omitempty"`
+	Input string `json:"input"`
+}
+
+// EmbeddingResponse соответствует структуре ответа эндпоинта эмбеддингов.
+type EmbeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed возвращает вектор для текста.
+func (eg *EmbeddingGenerator) Embed(text string) ([]float32, error) {
+	jsonBody, err := json.Marshal(EmbeddingRequest{Model: eg.Config.AI.Embeddings.Model, Input: text})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при маршалинге JSON запроса эмбеддинга: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", eg.Endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания HTTP запроса эмбеддинга: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if eg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+eg.APIKey)
+	}
+
+	resp, err := eg.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при отправке запроса к модели эмбеддингов: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении ответа модели эмбеддингов: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("получен некорректный статус от модели эмбеддингов: %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var responseData EmbeddingResponse
+	if err := json.Unmarshal(bodyBytes, &responseData); err != nil {
+		return nil, fmt.Errorf("ошибка при демаршалинге JSON ответа эмбеддинга: %w", err)
+	}
+	if len(responseData.Data) == 0 || len(responseData.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("ответ модели эмбеддингов не содержит вектора")
+	}
+	return responseData.Data[0].Embedding, nil
+}
+
+// CosineSimilarity возвращает косинусное сходство векторов (от -1 до 1).
+// Для векторов разной длины или нулевых векторов возвращает 0.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,121 @@
+// internal/ai/http.go
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPollInterval = 2 * time.Second
+	defaultPollTimeout  = 15 * time.Minute
+
+	// defaultSegmentSeconds — длительность сегмента для шаблонов, если планировщик ее не задал.
+	defaultSegmentSeconds = 5.0
+)
+
+// pollSettings подставляет значения по умолчанию для интервала и таймаута опроса.
+func pollSettings(interval, timeout time.Duration) (time.Duration, time.Duration) {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	if timeout <= 0 {
+		timeout = defaultPollTimeout
+	}
+	return interval, timeout
+}
+
+// getJSON выполняет GET-запрос и декодирует JSON-ответ в out.
+func getJSON(client *http.Client, url string, headers map[string]string, out interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса %s: %w", url, err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения ответа %s: %w", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("получен некорректный статус от %s: %d - %s", url, resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("ошибка разбора ответа %s: %w", url, err)
+	}
+	return nil
+}
+
+// requestTemplateVars возвращает значения для плейсхолдеров шаблонов запросов к видеомоделям.
+func requestTemplateVars(req VideoGenerationRequest) map[string]interface{} {
+	duration := req.Duration
+	if duration <= 0 {
+		duration = defaultSegmentSeconds
+	}
+	vars := map[string]interface{}{
+		"prompt":          req.Prompt,
+		"resolution":      req.Resolution,
+		"output_format":   req.OutputFormat,
+		"fps":             req.FPS,
+		"duration":        duration,
+		"frames":          int(duration * float64(req.FPS)),
+		"negative_prompt": req.NegativePrompt,
+		"model":           req.Model,
+		"seed":            req.Seed,
+		"guidance_scale":  req.GuidanceScale,
+		"steps":           req.Steps,
+		"motion_strength": req.MotionStrength,
+	}
+	if width, height, ok := strings.Cut(req.Resolution, "x"); ok {
+		var w, h int
+		fmt.Sscan(width, &w)
+		fmt.Sscan(height, &h)
+		vars["width"], vars["height"] = w, h
+	}
+	return vars
+}
+
+// fillTemplate рекурсивно заменяет плейсхолдеры {{name}} в JSON-структуре.
+// Строка, целиком состоящая из плейсхолдера, заменяется значением с исходным типом (число остается числом),
+// а плейсхолдер внутри строки подставляется как текст.
+func fillTemplate(value interface{}, vars map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = fillTemplate(item, vars)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = fillTemplate(item, vars)
+		}
+		return out
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") && strings.Count(trimmed, "{{") == 1 {
+			if replacement, ok := vars[strings.TrimSpace(trimmed[2:len(trimmed)-2])]; ok {
+				return replacement
+			}
+		}
+		for name, replacement := range vars {
+			v = strings.ReplaceAll(v, "{{"+name+"}}", fmt.Sprint(replacement))
+		}
+		return v
+	default:
+		return v
+	}
+}
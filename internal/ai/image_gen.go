@@ -0,0 +1,130 @@
+// internal/ai/image_gen.go
+package ai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/download"
+	"ai-content-gen/pkg/utils"
+)
+
+// ImageGenerator представляет интерфейс для нейросети генерации изображений
+// (OpenAI-совместимый эндпоинт /v1/images/generations).
+type ImageGenerator struct {
+	Endpoint   string
+	APIKey     string
+	Config     *config.AppConfig
+	Downloader *download.Downloader
+	Logger     *utils.Logger
+}
+
+// NewImageGenerator создает новый экземпляр ImageGenerator.
+func NewImageGenerator(endpoint, apiKey string, cfg *config.AppConfig, logger *utils.Logger) *ImageGenerator {
+	return &ImageGenerator{
+		Endpoint:   endpoint,
+		APIKey:     apiKey,
+		Config:     cfg,
+		Downloader: download.NewDownloader(cfg.Download, logger),
+		Logger:     logger,
+	}
+}
+
+// ImageGenerationRequest соответствует структуре запроса к нейросети изображений.
+type ImageGenerationRequest struct {
+	Model          string `json:"model,omitempty"`
+	Prompt         string `json:"prompt"`
+	Size           string `json:"size,omitempty"`
+	N              int    `json:"n"`
+	ResponseFormat string `json:"response_format"`
+}
+
+// ImageGenerationResponse соответствует структуре ответа от нейросети изображений.
+type ImageGenerationResponse struct {
+	Data []struct {
+		B64JSON string `json:"b64_json"`
+		URL     string `json:"url"`
+	} `json:"data"`
+}
+
+// GenerateImage генерирует изображение по промпту и сохраняет его в outputPath.
+// Возвращает путь к сохраненному файлу.
+func (ig *ImageGenerator) GenerateImage(prompt, outputPath string) (string, error) {
+	ig.Logger.Info("Запрос на генерацию изображения: %s", prompt)
+
+	requestBody := ImageGenerationRequest{
+		Model:          ig.Config.AI.Image.Model,
+		Prompt:         prompt,
+		Size:           ig.Config.AI.Image.Size,
+		N:              1,
+		ResponseFormat: "b64_json",
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при маршалинге JSON запроса для изображения: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", ig.Endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("ошибка создания HTTP запроса для изображения: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ig.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+ig.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при отправке запроса к нейросети изображений: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при чтении ответа от нейросети изображений: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("получен некорректный статус от нейросети изображений: %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var responseData ImageGenerationResponse
+	if err := json.Unmarshal(bodyBytes, &responseData); err != nil {
+		return "", fmt.Errorf("ошибка при демаршалинге JSON ответа изображения: %w\nОтвет: %s", err, string(bodyBytes))
+	}
+	if len(responseData.Data) == 0 {
+		return "", fmt.Errorf("не найдено 'data' в ответе от нейросети изображений")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("не удалось создать директорию для изображения: %w", err)
+	}
+
+	image := responseData.Data[0]
+	switch {
+	case image.B64JSON != "":
+		data, err := base64.StdEncoding.DecodeString(image.B64JSON)
+		if err != nil {
+			return "", fmt.Errorf("ошибка декодирования base64 изображения: %w", err)
+		}
+		if err := os.WriteFile(outputPath, data, 0o644); err != nil {
+			return "", fmt.Errorf("ошибка записи изображения: %w", err)
+		}
+	case image.URL != "":
+		if err := ig.Downloader.Download(download.Request{URL: image.URL, Path: outputPath, ContentTypes: []string{"image/"}}); err != nil {
+			return "", fmt.Errorf("ошибка при скачивании изображения: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("ответ нейросети изображений не содержит ни b64_json, ни url")
+	}
+
+	ig.Logger.Info("Изображение сгенерировано и сохранено: %s", outputPath)
+	return outputPath, nil
+}
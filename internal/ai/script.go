@@ -0,0 +1,152 @@
+// internal/ai/script.go
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+// Scene описывает одну сцену сценария.
+type Scene struct {
+	Description string
+	Duration    float64            // Длительность в секундах; 0, если модель ее не указала
+	Overlay     string             // Надпись поверх сцены; пусто, если не нужна
+	Params      config.VideoParams // Переопределения параметров генерации для сцены
+}
+
+// Script представляет разобранный ответ текстовой модели: общую идею и список сцен.
+type Script struct {
+	Idea         string
+	Hook         string // Цепляющая фраза на первые секунды ролика
+	CallToAction string // Призыв к действию в конце ролика
+	Scenes       []Scene
+}
+
+var (
+	ideaRegex    = regexp.MustCompile(`^Идея:\s*(.+)`)
+	sceneRegex   = regexp.MustCompile(`^Сцена \d+(?:\s*\(\s*(\d+(?:[.,]\d+)?)\s*(?:с|сек|s)[^)]*\))?\s*:\s*(.*)`)
+	hookRegex    = regexp.MustCompile(`^Хук:\s*(.+)`)
+	ctaRegex     = regexp.MustCompile(`^Призыв:\s*(.+)`)
+	overlayRegex = regexp.MustCompile(`^Надпись:\s*(.+)`)
+	paramsRegex  = regexp.MustCompile(`^Параметры:\s*(.+)`)
+)
+
+// ParseScript разбирает сгенерированный текст на общую идею и отдельные описания сцен.
+// Строки сцен имеют вид "Сцена N: описание" или "Сцена N (8 сек): описание".
+// Строки "Надпись: текст" и "Параметры: seed=42; steps=40" относятся к предыдущей сцене,
+// "Хук:" и "Призыв:" — ко всему ролику.
+func ParseScript(content string, logger *utils.Logger) *Script {
+	script := &Script{}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if matches := ideaRegex.FindStringSubmatch(line); len(matches) > 1 {
+			script.Idea = matches[1]
+			logger.Info("Извлечена идея: %s", script.Idea)
+		} else if matches := sceneRegex.FindStringSubmatch(line); len(matches) > 2 {
+			// Добавляем проверку на наличие содержимого после "Сцена N: "
+			if matches[2] == "" {
+				logger.Warn("Пустое описание для сцены в строке: %s", line)
+				continue
+			}
+			scene := Scene{Description: matches[2]}
+			if matches[1] != "" {
+				if d, err := strconv.ParseFloat(strings.Replace(matches[1], ",", ".", 1), 64); err == nil {
+					scene.Duration = d
+				}
+			}
+			script.Scenes = append(script.Scenes, scene)
+			logger.Info("Извлечена сцена (%.1f с): %s", scene.Duration, scene.Description)
+		} else if matches := overlayRegex.FindStringSubmatch(line); len(matches) > 1 {
+			if len(script.Scenes) == 0 {
+				logger.Warn("Надпись до первой сцены проигнорирована: %s", line)
+				continue
+			}
+			script.Scenes[len(script.Scenes)-1].Overlay = trimQuotes(matches[1])
+		} else if matches := paramsRegex.FindStringSubmatch(line); len(matches) > 1 {
+			if len(script.Scenes) == 0 {
+				logger.Warn("Параметры до первой сцены проигнорированы: %s", line)
+				continue
+			}
+			params, err := ParseVideoParams(matches[1])
+			if err != nil {
+				logger.Warn("Некорректные параметры сцены %d: %v", len(script.Scenes), err)
+				continue
+			}
+			script.Scenes[len(script.Scenes)-1].Params = params
+			logger.Info("Параметры генерации для сцены %d: %+v", len(script.Scenes), params)
+		} else if matches := hookRegex.FindStringSubmatch(line); len(matches) > 1 {
+			script.Hook = trimQuotes(matches[1])
+			logger.Info("Извлечен хук: %s", script.Hook)
+		} else if matches := ctaRegex.FindStringSubmatch(line); len(matches) > 1 {
+			script.CallToAction = trimQuotes(matches[1])
+			logger.Info("Извлечен призыв к действию: %s", script.CallToAction)
+		}
+	}
+
+	if script.Idea == "" {
+		logger.Warn("Не удалось найти 'Идея:' в сгенерированном контенте.")
+	}
+	if len(script.Scenes) == 0 {
+		logger.Warn("Не удалось найти ни одной 'Сцены' в сгенерированном контенте.")
+	}
+
+	return script
+}
+
+// trimQuotes убирает кавычки, которыми модель иногда обрамляет короткие фразы.
+func trimQuotes(text string) string {
+	return strings.Trim(strings.TrimSpace(text), `"«»`)
+}
+
+// ParseVideoParams разбирает параметры генерации вида "seed=42; steps=40; negative_prompt=blurry, text".
+// Пары разделяются точкой с запятой, чтобы запятые оставались допустимыми в negative_prompt.
+func ParseVideoParams(text string) (config.VideoParams, error) {
+	var params config.VideoParams
+	for _, pair := range strings.Split(text, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return params, fmt.Errorf("ожидается ключ=значение, получено %q", pair)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "model":
+			params.Model = value
+		case "seed":
+			params.Seed, err = strconv.ParseInt(value, 10, 64)
+		case "negative_prompt", "negative":
+			params.NegativePrompt = value
+		case "duration":
+			params.Duration, err = strconv.ParseFloat(value, 64)
+		case "guidance_scale", "guidance", "cfg":
+			params.GuidanceScale, err = strconv.ParseFloat(value, 64)
+		case "steps":
+			params.Steps, err = strconv.Atoi(value)
+		case "motion_strength", "motion":
+			params.MotionStrength, err = strconv.ParseFloat(value, 64)
+		case "reference_image", "image":
+			params.ReferenceImage = value
+		default:
+			return params, fmt.Errorf("неизвестный параметр %q", key)
+		}
+		if err != nil {
+			return params, fmt.Errorf("некорректное значение %s=%q: %w", key, value, err)
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,69 @@
+// internal/ai/style_bible.go
+package ai
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StyleBible фиксирует визуальные правила ролика — повторяющихся персонажей, палитру и стиль,
+// — чтобы сцены, которые генерируются независимо, выглядели единообразно.
+type StyleBible struct {
+	Characters string
+	Palette    string
+	Style      string
+	Raw        string // Полный ответ модели
+}
+
+var (
+	charactersRegex = regexp.MustCompile(`^Персонажи:\s*(.+)`)
+	paletteRegex    = regexp.MustCompile(`^Палитра:\s*(.+)`)
+	styleRegex      = regexp.MustCompile(`^Стиль:\s*(.+)`)
+)
+
+// ParseStyleBible разбирает ответ модели в формате "Персонажи: ... / Палитра: ... / Стиль: ...".
+func ParseStyleBible(content string) *StyleBible {
+	bible := &StyleBible{Raw: strings.TrimSpace(content)}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if matches := charactersRegex.FindStringSubmatch(line); len(matches) > 1 {
+			bible.Characters = matches[1]
+		} else if matches := paletteRegex.FindStringSubmatch(line); len(matches) > 1 {
+			bible.Palette = matches[1]
+		} else if matches := styleRegex.FindStringSubmatch(line); len(matches) > 1 {
+			bible.Style = matches[1]
+		}
+	}
+	return bible
+}
+
+// String возвращает описание для вставки в промпты текстовой модели.
+func (b *StyleBible) String() string {
+	if b == nil {
+		return ""
+	}
+	if b.Characters == "" && b.Palette == "" && b.Style == "" {
+		return b.Raw
+	}
+	return strings.Join([]string{
+		"Персонажи: " + b.Characters,
+		"Палитра: " + b.Palette,
+		"Стиль: " + b.Style,
+	}, "\n")
+}
+
+// PromptSuffix возвращает краткое описание стиля и палитры, которое дописывается
+// к каждому видео-промпту, даже если текстовая модель опустила эти детали.
+func (b *StyleBible) PromptSuffix() string {
+	if b == nil {
+		return ""
+	}
+	var parts []string
+	if b.Style != "" {
+		parts = append(parts, "Style: "+b.Style)
+	}
+	if b.Palette != "" {
+		parts = append(parts, "Color palette: "+b.Palette)
+	}
+	return strings.Join(parts, ". ")
+}
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
+	"ai-content-gen/internal/cache"
 	"ai-content-gen/internal/config" // Импортируем конфиг
 	"ai-content-gen/pkg/utils"
 )
@@ -28,52 +31,194 @@ type OpenAICompletionResponse struct {
 type TextGenerator struct {
 	Endpoint string
 	Config   *config.AppConfig // Ссылка на AppConfig
+	Cache    *cache.Cache      // nil, если кэш выключен
+	OnCall   TextCallFunc      // Необязательный обработчик каждого запроса (для манифеста запуска)
+	Style    string            // Необязательный стиль ролика от заказчика, добавляется в промпты сценария и сцен
 	Logger   *utils.Logger
 }
 
+// TextCall описывает один запрос к текстовой модели и ее ответ.
+type TextCall struct {
+	Stage       string        `json:"stage"` // script, style_bible, scene_prompt, thumbnail_title, topic_score
+	Model       string        `json:"model"`
+	Prompt      string        `json:"prompt"`
+	Response    string        `json:"response,omitempty"`
+	MaxTokens   int           `json:"max_tokens"`
+	Temperature float64       `json:"temperature"`
+	Cached      bool          `json:"cached,omitempty"`
+	StartedAt   time.Time     `json:"started_at"`
+	Duration    time.Duration `json:"duration_ns"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// TextCallFunc получает сведения о каждом запросе к текстовой модели.
+type TextCallFunc func(TextCall)
+
 // NewTextGenerator создает новый экземпляр TextGenerator.
 func NewTextGenerator(endpoint string, cfg *config.AppConfig, logger *utils.Logger) *TextGenerator {
 	return &TextGenerator{
 		Endpoint: endpoint,
 		Config:   cfg,
+		Cache:    cache.New(cfg.Cache, logger),
 		Logger:   logger,
 	}
 }
 
 // GenerateShortsIdeaAndScenes генерирует общую идею и краткое описание сцен для YouTube Shorts.
-func (tg *TextGenerator) GenerateShortsIdeaAndScenes(topic string) (string, error) {
+// avoidIdeas: уже использованные идеи, на которые новая не должна быть похожа (может быть пустым).
+func (tg *TextGenerator) GenerateShortsIdeaAndScenes(topic string, avoidIdeas []string) (string, error) {
 	tg.Logger.Info("Запрос на генерацию общей идеи и сцен для темы: %s", topic)
 
+	avoidHint := ""
+	if len(avoidIdeas) > 0 {
+		var list strings.Builder
+		for _, idea := range avoidIdeas {
+			fmt.Fprintf(&list, "- %s\n", idea)
+		}
+		avoidHint = fmt.Sprintf("Эти идеи уже были, придумай заметно отличающуюся по сюжету и образам:\n%s", list.String())
+	}
+
+	durationHint := ""
+	if d := tg.Config.AI.Video.Duration; d.Target > 0 {
+		durationHint = fmt.Sprintf("Общая длительность ролика — около %.0f секунд, каждая сцена длится от %.0f до %.0f секунд.\n", d.Target, d.MinScene, d.MaxScene)
+	}
 	promptContent := fmt.Sprintf(`Придумай идею для YouTube Shorts про "%s".
-Формат ответа строго следующий:
+%s%s%sФормат ответа строго следующий:
 Идея: [краткое описание идеи]
+Хук: [цепляющая фраза на экране в первые секунды, до 6 слов]
 
-Сцена 1: [краткое описание]
-Сцена 2: [краткое описание]
-Сцена 3: [краткое описание]
+Сцена 1 ([длительность] сек): [краткое описание]
+Надпись: [короткая подпись на экране к сцене, до 5 слов, или пропусти строку]
+Сцена 2 ([длительность] сек): [краткое описание]
+Надпись: [короткая подпись к сцене]
+Сцена 3 ([длительность] сек): [краткое описание]
 ... (до 5-7 сцен, если уместно)
-`, topic)
+
+Призыв: [короткий призыв к действию в конце ролика, до 5 слов]
+`, topic, tg.styleHint(), durationHint, avoidHint)
 
 	// Используем max_tokens_general из конфигурации
-	return tg.callAI(promptContent, tg.Config.AI.Text.MaxTokensGeneral)
+	return tg.callAI("script", promptContent, tg.Config.AI.Text.MaxTokensGeneral)
+}
+
+// GenerateStyleBible один раз определяет визуальные правила ролика: повторяющихся персонажей,
+// палитру и стиль. Результат передается в GenerateVideoPromptForScene для каждой сцены.
+func (tg *TextGenerator) GenerateStyleBible(script *Script) (*StyleBible, error) {
+	tg.Logger.Info("Запрос на генерацию стилевой библии для идеи: %s", script.Idea)
+
+	var scenes strings.Builder
+	for i, scene := range script.Scenes {
+		fmt.Fprintf(&scenes, "Сцена %d: %s\n", i+1, scene.Description)
+	}
+
+	promptContent := fmt.Sprintf(`Для YouTube Shorts с идеей "%s" и сценами:
+%s
+составь стилевую библию, единую для всех сцен, чтобы видео выглядело как один ролик.
+Формат ответа строго следующий (каждый пункт одной строкой):
+Персонажи: [внешность каждого повторяющегося персонажа или объекта: форма, одежда, цвета, отличительные черты]
+Палитра: [3-5 основных цветов]
+Стиль: [художественный стиль, освещение, тип камеры и оптики]
+`, script.Idea, scenes.String())
+
+	content, err := tg.callAI("style_bible", promptContent, tg.Config.AI.Text.MaxTokensGeneral)
+	if err != nil {
+		return nil, err
+	}
+	bible := ParseStyleBible(content)
+	tg.Logger.Info("Стилевая библия:\n%s", bible.String())
+	return bible, nil
 }
 
 // GenerateVideoPromptForScene генерирует подробный промпт для видеогенерации конкретной сцены.
-func (tg *TextGenerator) GenerateVideoPromptForScene(overallIdea, sceneDescription string) (string, error) {
+// bible может быть nil; если задана, персонажи и стиль описываются строго по ней.
+func (tg *TextGenerator) GenerateVideoPromptForScene(overallIdea, sceneDescription string, bible *StyleBible) (string, error) {
 	tg.Logger.Info("Запрос на генерацию подробного промпта для видео-сцены: %s", sceneDescription)
 
+	bibleHint := ""
+	if bible != nil {
+		bibleHint = fmt.Sprintf(`Строго соблюдай стилевую библию ролика — персонажи, палитра и стиль должны совпадать во всех сценах:
+%s
+`, bible.String())
+	}
+
 	promptContent := fmt.Sprintf(`На основе общей идеи "%s" и описания сцены "%s",
 создай очень подробный и детализированный промпт, пригодный для прямой генерации видео.
 Опиши: что происходит в кадре, какие объекты присутствуют, их действия, фон, освещение, настроение, стиль.
 Сфокусируйся на визуальных деталях.
-`, overallIdea, sceneDescription)
+%s%s`, overallIdea, sceneDescription, tg.styleHint(), bibleHint)
 
 	// Используем max_tokens_detailed из конфигурации
-	return tg.callAI(promptContent, tg.Config.AI.Text.MaxTokensDetailed)
+	prompt, err := tg.callAI("scene_prompt", promptContent, tg.Config.AI.Text.MaxTokensDetailed)
+	if err != nil {
+		return "", err
+	}
+	if suffix := bible.PromptSuffix(); suffix != "" {
+		prompt = strings.TrimSpace(prompt) + "\n" + suffix
+	}
+	return prompt, nil
+}
+
+// GenerateThumbnailTitle генерирует короткий цепляющий заголовок для обложки ролика.
+func (tg *TextGenerator) GenerateThumbnailTitle(overallIdea string) (string, error) {
+	tg.Logger.Info("Запрос на генерацию заголовка обложки для идеи: %s", overallIdea)
+
+	promptContent := fmt.Sprintf(`Придумай цепляющий заголовок для обложки YouTube Shorts на основе идеи "%s".
+Не более 5 слов, без кавычек, хэштегов и эмодзи. В ответе — только сам заголовок.
+`, overallIdea)
+
+	title, err := tg.callAI("thumbnail_title", promptContent, 64)
+	if err != nil {
+		return "", err
+	}
+	return trimQuotes(title), nil
+}
+
+// styleHint возвращает строку промпта со стилем ролика или пустую строку, если стиль не задан.
+func (tg *TextGenerator) styleHint() string {
+	if tg.Style == "" {
+		return ""
+	}
+	return fmt.Sprintf("Стиль ролика: %s.\n", tg.Style)
 }
 
+// uncachedStages — этапы, ответ на которые должен быть новым при каждом запуске:
+// идея из кэша повторяла бы прошлый ролик на ту же тему.
+var uncachedStages = map[string]bool{"script": true}
+
 // callAI является внутренней функцией для отправки запросов к локальной модели.
-func (tg *TextGenerator) callAI(content string, maxTokens int) (string, error) {
+// stage: этап конвейера, к которому относится запрос (для манифеста запуска).
+// Ответы кэшируются по эндпоинту, модели, промпту и параметрам генерации, кроме этапов из uncachedStages.
+func (tg *TextGenerator) callAI(stage, content string, maxTokens int) (string, error) {
+	call := TextCall{
+		Stage:       stage,
+		Model:       tg.Config.AI.Text.Model,
+		Prompt:      content,
+		MaxTokens:   maxTokens,
+		Temperature: tg.Config.AI.Text.Temperature,
+		StartedAt:   time.Now(),
+	}
+	response, cached, err := tg.request(content, maxTokens, !uncachedStages[stage])
+	if tg.OnCall != nil {
+		call.Response, call.Cached, call.Duration = response, cached, time.Since(call.StartedAt)
+		if err != nil {
+			call.Error = err.Error()
+		}
+		tg.OnCall(call)
+	}
+	return response, err
+}
+
+// request отправляет запрос к модели или, если useCache, берет ответ из кэша.
+// Второе значение сообщает, был ли ответ в кэше.
+func (tg *TextGenerator) request(content string, maxTokens int, useCache bool) (string, bool, error) {
+	cacheKey := cache.Key("text", tg.Endpoint, tg.Config.AI.Text.Model, content, maxTokens, tg.Config.AI.Text.Temperature)
+	if useCache {
+		if cached, ok := tg.Cache.Get(cacheKey); ok {
+			tg.Logger.Info("Ответ текстовой нейросети взят из кэша")
+			return string(cached), true, nil
+		}
+	}
+
 	requestBody := map[string]interface{}{
 		"model":                tg.Config.AI.Text.Model, // Модель из YAML
 		"chat_template_kwargs": map[string]bool{"enable_thinking": false},
@@ -89,34 +234,38 @@ func (tg *TextGenerator) callAI(content string, maxTokens int) (string, error) {
 
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
-		return "", fmt.Errorf("ошибка при маршалинге JSON запроса: %w", err)
+		return "", false, fmt.Errorf("ошибка при маршалинге JSON запроса: %w", err)
 	}
 
 	resp, err := http.Post(tg.Endpoint, "application/json", bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("ошибка при отправке запроса к текстовой нейросети: %w", err)
+		return "", false, fmt.Errorf("ошибка при отправке запроса к текстовой нейросети: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("получен некорректный статус от текстовой нейросети: %d - %s", resp.StatusCode, string(bodyBytes))
+		return "", false, fmt.Errorf("получен некорректный статус от текстовой нейросети: %d - %s", resp.StatusCode, string(bodyBytes))
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("ошибка при чтении ответа от текстовой нейросети: %w", err)
+		return "", false, fmt.Errorf("ошибка при чтении ответа от текстовой нейросети: %w", err)
 	}
 
 	var responseData OpenAICompletionResponse
 	err = json.Unmarshal(bodyBytes, &responseData)
 	if err != nil {
-		return "", fmt.Errorf("ошибка при демаршалинге JSON ответа: %w\nОтвет: %s", err, string(bodyBytes))
+		return "", false, fmt.Errorf("ошибка при демаршалинге JSON ответа: %w\nОтвет: %s", err, string(bodyBytes))
 	}
 
 	if len(responseData.Choices) == 0 {
-		return "", fmt.Errorf("не найдено 'choices' в ответе от текстовой нейросети")
+		return "", false, fmt.Errorf("не найдено 'choices' в ответе от текстовой нейросети")
 	}
 
-	return responseData.Choices[0].Message.Content, nil
+	result := responseData.Choices[0].Message.Content
+	if useCache {
+		tg.Cache.Put(cacheKey, []byte(result))
+	}
+	return result, false, nil
 }
@@ -0,0 +1,68 @@
+// internal/ai/topic_score.go
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// topicScoreBatch — сколько тем оценивается одним запросом, чтобы ответ не обрезался по max_tokens.
+const topicScoreBatch = 20
+
+var topicScoreRegex = regexp.MustCompile(`^\s*(\d+)\s*[:.)\-]\s*(\d+(?:[.,]\d+)?)`)
+
+// ScoreTopics оценивает темы по шкале 0-10: насколько из каждой получится цепляющий короткий ролик для канала.
+// Возвращает оценки в порядке тем; тема, для которой модель не вернула оценку, получает 0.
+func (tg *TextGenerator) ScoreTopics(channel string, topics []string) ([]float64, error) {
+	scores := make([]float64, len(topics))
+	for start := 0; start < len(topics); start += topicScoreBatch {
+		end := min(start+topicScoreBatch, len(topics))
+		tg.Logger.Info("Запрос на оценку тем %d-%d из %d для канала %s", start+1, end, len(topics), channel)
+
+		var list strings.Builder
+		for i, topic := range topics[start:end] {
+			fmt.Fprintf(&list, "%d. %s\n", i+1, topic)
+		}
+		promptContent := fmt.Sprintf(`Ты редактор канала коротких вертикальных видео (YouTube Shorts, TikTok) "%s".
+Оцени каждую тему по шкале от 0 до 10: насколько из нее получится зрелищный ролик до 60 секунд,
+который зацепит зрителя в первые секунды и подходит тематике канала.
+Темы:
+%s
+Ответь строго в формате "номер: оценка", по одной теме в строке, без пояснений.
+`, channel, list.String())
+
+		content, err := tg.callAI("topic_score", promptContent, 16*(end-start)+32)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка оценки тем: %w", err)
+		}
+		for number, score := range ParseTopicScores(content) {
+			if number >= 1 && number <= end-start {
+				scores[start+number-1] = score
+			}
+		}
+	}
+	return scores, nil
+}
+
+// ParseTopicScores разбирает ответ вида "1: 7" / "2. 8.5" в словарь номер → оценка (ограничена диапазоном 0-10).
+func ParseTopicScores(content string) map[int]float64 {
+	scores := make(map[int]float64)
+	for _, line := range strings.Split(content, "\n") {
+		matches := topicScoreRegex.FindStringSubmatch(line)
+		if len(matches) < 3 {
+			continue
+		}
+		number, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+		score, err := strconv.ParseFloat(strings.Replace(matches[2], ",", ".", 1), 64)
+		if err != nil {
+			continue
+		}
+		scores[number] = max(0, min(score, 10))
+	}
+	return scores
+}
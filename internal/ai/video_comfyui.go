@@ -0,0 +1,250 @@
+// internal/ai/video_comfyui.go
+package ai
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+// ComfyUIProvider запускает workflow через API ComfyUI: POST /prompt, затем опрос /history/{id}
+// и скачивание результата через /view.
+type ComfyUIProvider struct {
+	BaseURL string
+	Config  config.ComfyUIConfig
+	Client  *http.Client
+	Logger  *utils.Logger
+}
+
+// NewComfyUIProvider создает новый экземпляр ComfyUIProvider.
+// baseURL: адрес сервера ComfyUI, например http://localhost:8188.
+func NewComfyUIProvider(baseURL string, cfg config.ComfyUIConfig, logger *utils.Logger) *ComfyUIProvider {
+	return &ComfyUIProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Config:  cfg,
+		Client:  &http.Client{Timeout: 60 * time.Second},
+		Logger:  logger,
+	}
+}
+
+// Name возвращает имя провайдера.
+func (p *ComfyUIProvider) Name() string {
+	return "comfyui"
+}
+
+// comfyHistoryEntry — запись /history/{prompt_id}.
+type comfyHistoryEntry struct {
+	Status struct {
+		StatusStr string          `json:"status_str"`
+		Completed bool            `json:"completed"`
+		Messages  [][]interface{} `json:"messages"`
+	} `json:"status"`
+	Outputs map[string]map[string]json.RawMessage `json:"outputs"`
+}
+
+// comfyFile — описание файла в выходах узла ComfyUI.
+type comfyFile struct {
+	Filename  string `json:"filename"`
+	Subfolder string `json:"subfolder"`
+	Type      string `json:"type"`
+}
+
+// Generate подставляет параметры запроса в workflow, ставит его в очередь и ждет результата.
+func (p *ComfyUIProvider) Generate(req VideoGenerationRequest) (*VideoResult, error) {
+	workflow, err := p.buildWorkflow(req)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := make([]byte, 16)
+	_, _ = rand.Read(clientID)
+	body, err := json.Marshal(map[string]interface{}{
+		"prompt":    workflow,
+		"client_id": hex.EncodeToString(clientID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка маршалинга workflow ComfyUI: %w", err)
+	}
+
+	resp, err := p.Client.Post(p.BaseURL+"/prompt", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка отправки workflow в ComfyUI: %w", err)
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ComfyUI отклонил workflow: %d - %s", resp.StatusCode, string(respBody))
+	}
+
+	var queued struct {
+		PromptID string `json:"prompt_id"`
+	}
+	if err := json.Unmarshal(respBody, &queued); err != nil || queued.PromptID == "" {
+		return nil, fmt.Errorf("не удалось получить prompt_id от ComfyUI: %v\nОтвет: %s", err, string(respBody))
+	}
+	p.Logger.Info("Workflow поставлен в очередь ComfyUI: %s", queued.PromptID)
+
+	entry, err := p.waitForHistory(queued.PromptID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := p.findOutputFile(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("filename", file.Filename)
+	query.Set("subfolder", file.Subfolder)
+	query.Set("type", file.Type)
+	// Анимации из выхода "gifs" ComfyUI отдает как image/gif
+	return &VideoResult{URL: p.BaseURL + "/view?" + query.Encode(), ContentTypes: []string{"video/", "image/gif"}}, nil
+}
+
+// buildWorkflow загружает workflow и подставляет значения вместо плейсхолдеров {{name}}.
+func (p *ComfyUIProvider) buildWorkflow(req VideoGenerationRequest) (interface{}, error) {
+	raw, err := os.ReadFile(p.Config.Workflow)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения workflow ComfyUI %s: %w", p.Config.Workflow, err)
+	}
+
+	var workflow interface{}
+	if err := json.Unmarshal(raw, &workflow); err != nil {
+		return nil, fmt.Errorf("ошибка разбора workflow ComfyUI %s: %w", p.Config.Workflow, err)
+	}
+
+	vars := requestTemplateVars(req)
+	if req.ReferenceImage != "" {
+		name, err := p.uploadImage(req.ReferenceImage)
+		if err != nil {
+			return nil, err
+		}
+		vars["reference_image"] = name
+	}
+	return fillTemplate(workflow, vars), nil
+}
+
+// uploadImage загружает референс-изображение в ComfyUI (POST /upload/image) и возвращает имя,
+// под которым его можно указать в узле LoadImage через плейсхолдер {{reference_image}}.
+func (p *ComfyUIProvider) uploadImage(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("не удалось открыть референс-изображение %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("image", filepath.Base(path))
+	if err != nil {
+		return "", fmt.Errorf("ошибка формирования запроса загрузки изображения: %w", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return "", fmt.Errorf("ошибка чтения референс-изображения: %w", err)
+	}
+	_ = writer.WriteField("overwrite", "true")
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("ошибка формирования запроса загрузки изображения: %w", err)
+	}
+
+	resp, err := p.Client.Post(p.BaseURL+"/upload/image", writer.FormDataContentType(), &body)
+	if err != nil {
+		return "", fmt.Errorf("ошибка загрузки изображения в ComfyUI: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ComfyUI отклонил изображение: %d - %s", resp.StatusCode, string(respBody))
+	}
+
+	var uploaded struct {
+		Name      string `json:"name"`
+		Subfolder string `json:"subfolder"`
+	}
+	if err := json.Unmarshal(respBody, &uploaded); err != nil || uploaded.Name == "" {
+		return "", fmt.Errorf("не удалось разобрать ответ загрузки изображения: %s", string(respBody))
+	}
+	if uploaded.Subfolder != "" {
+		return uploaded.Subfolder + "/" + uploaded.Name, nil
+	}
+	return uploaded.Name, nil
+}
+
+// waitForHistory опрашивает /history/{id}, пока генерация не завершится или не истечет таймаут.
+func (p *ComfyUIProvider) waitForHistory(promptID string) (*comfyHistoryEntry, error) {
+	interval, timeout := pollSettings(p.Config.PollInterval, p.Config.Timeout)
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		var history map[string]comfyHistoryEntry
+		if err := getJSON(p.Client, p.BaseURL+"/history/"+url.PathEscape(promptID), nil, &history); err != nil {
+			p.Logger.Warn("Ошибка опроса истории ComfyUI: %v", err)
+		} else if entry, ok := history[promptID]; ok {
+			switch {
+			case entry.Status.StatusStr == "error":
+				return nil, fmt.Errorf("ComfyUI завершил workflow %s с ошибкой: %v", promptID, entry.Status.Messages)
+			case entry.Status.Completed || len(entry.Outputs) > 0:
+				return &entry, nil
+			}
+		}
+		time.Sleep(interval)
+	}
+	return nil, fmt.Errorf("истекло время ожидания результата ComfyUI (%s) для %s", timeout, promptID)
+}
+
+// findOutputFile находит видеофайл среди выходов workflow.
+// ComfyUI и популярные расширения кладут видео в "videos", "gifs" или "images".
+func (p *ComfyUIProvider) findOutputFile(entry *comfyHistoryEntry) (*comfyFile, error) {
+	nodeIDs := make([]string, 0, len(entry.Outputs))
+	for id := range entry.Outputs {
+		if p.Config.OutputNode == "" || id == p.Config.OutputNode {
+			nodeIDs = append(nodeIDs, id)
+		}
+	}
+	sort.Strings(nodeIDs)
+
+	for _, id := range nodeIDs {
+		for _, key := range []string{"videos", "gifs", "images"} {
+			raw, ok := entry.Outputs[id][key]
+			if !ok {
+				continue
+			}
+			var files []comfyFile
+			if err := json.Unmarshal(raw, &files); err != nil {
+				continue
+			}
+			for _, f := range files {
+				if f.Filename != "" && (key != "images" || isVideoFile(f.Filename)) {
+					return &f, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("в выходах ComfyUI не найдено видео")
+}
+
+// isVideoFile проверяет расширение файла на принадлежность к видеоформатам.
+func isVideoFile(name string) bool {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".mp4", ".webm", ".mov", ".mkv", ".gif"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+// internal/ai/video_fallback.go
+package ai
+
+import (
+	"sync"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+// namedProvider переименовывает провайдера, чтобы два бэкенда одного типа
+// (например, два generic-эндпоинта) различались в логах и учете отказов.
+type namedProvider struct {
+	VideoProvider
+	name string
+}
+
+func (p namedProvider) Name() string { return p.name }
+
+// newBackendChain создает провайдеры из ai.video.fallback.backends в порядке приоритета.
+// Если список пуст, цепочка состоит из одного провайдера ai.video.provider.
+func newBackendChain(endpoint, apiKey string, cfg *config.AppConfig, logger *utils.Logger) []VideoProvider {
+	backends := cfg.AI.Video.Fallback.Backends
+	if len(backends) == 0 {
+		return []VideoProvider{NewVideoProvider(cfg.AI.Video.Provider, endpoint, apiKey, cfg, logger)}
+	}
+	chain := make([]VideoProvider, 0, len(backends))
+	for _, backend := range backends {
+		provider := NewVideoProvider(backend.Provider, backend.Endpoint, backend.APIKey, cfg, logger)
+		chain = append(chain, namedProvider{VideoProvider: provider, name: backend.Name})
+	}
+	return chain
+}
+
+// BackendHealth отслеживает отказы видеобэкендов. После MaxFailures неудач подряд бэкенд
+// исключается из цепочки на Cooldown, затем снова получает одну попытку.
+type BackendHealth struct {
+	MaxFailures int
+	Cooldown    time.Duration
+
+	mu       sync.Mutex
+	failures map[string]int
+	disabled map[string]time.Time // Бэкенд пропускается до указанного момента
+}
+
+// NewBackendHealth создает новый экземпляр BackendHealth.
+// Нулевые значения заменяются на 3 отказа и 10 минут.
+func NewBackendHealth(maxFailures int, cooldown time.Duration) *BackendHealth {
+	if maxFailures <= 0 {
+		maxFailures = 3
+	}
+	if cooldown <= 0 {
+		cooldown = 10 * time.Minute
+	}
+	return &BackendHealth{
+		MaxFailures: maxFailures,
+		Cooldown:    cooldown,
+		failures:    make(map[string]int),
+		disabled:    make(map[string]time.Time),
+	}
+}
+
+// Available сообщает, можно ли сейчас отправлять запросы бэкенду, и до какого момента он отключен.
+func (h *BackendHealth) Available(name string) (bool, time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	until, ok := h.disabled[name]
+	if !ok || time.Now().After(until) {
+		return true, time.Time{}
+	}
+	return false, until
+}
+
+// RecordSuccess сбрасывает счетчик отказов бэкенда.
+func (h *BackendHealth) RecordSuccess(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.failures, name)
+	delete(h.disabled, name)
+}
+
+// RecordFailure учитывает отказ и возвращает true, если бэкенд только что отключен.
+func (h *BackendHealth) RecordFailure(name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures[name]++
+	if h.failures[name] < h.MaxFailures {
+		return false
+	}
+	// После паузы одной неудачи достаточно, чтобы снова отключить бэкенд
+	h.failures[name] = h.MaxFailures - 1
+	h.disabled[name] = time.Now().Add(h.Cooldown)
+	return true
+}
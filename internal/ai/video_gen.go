@@ -2,16 +2,16 @@
 package ai
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"math/rand"
 	"path/filepath"
-	"strings"
+	"time"
 
+	"ai-content-gen/internal/cache"
 	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/download"
 	"ai-content-gen/pkg/utils"
 )
 
@@ -20,124 +20,203 @@ type VideoGenerator struct {
 	Endpoint string
 	APIKey   string
 	Config   *config.AppConfig // Ссылка на AppConfig
-	Logger   *utils.Logger
+	// Providers — адаптеры видеобэкендов в порядке приоритета (ai.video.fallback.backends
+	// или единственный ai.video.provider); Health учитывает их отказы.
+	Providers  []VideoProvider
+	Health     *BackendHealth
+	Downloader *download.Downloader
+	Cache      *cache.Cache // nil, если кэш выключен
+	Logger     *utils.Logger
 }
 
 // NewVideoGenerator создает новый экземпляр VideoGenerator.
 func NewVideoGenerator(endpoint, apiKey string, cfg *config.AppConfig, logger *utils.Logger) *VideoGenerator {
 	return &VideoGenerator{
-		Endpoint: endpoint,
-		APIKey:   apiKey,
-		Config:   cfg,
-		Logger:   logger,
+		Endpoint:   endpoint,
+		APIKey:     apiKey,
+		Config:     cfg,
+		Providers:  newBackendChain(endpoint, apiKey, cfg, logger),
+		Health:     NewBackendHealth(cfg.AI.Video.Fallback.MaxFailures, cfg.AI.Video.Fallback.Cooldown),
+		Downloader: download.NewDownloader(cfg.Download, logger),
+		Cache:      cache.New(cfg.Cache, logger),
+		Logger:     logger,
 	}
 }
 
 // VideoGenerationRequest соответствует структуре запроса к вашей видео-нейросети.
 type VideoGenerationRequest struct {
-	Prompt       string `json:"prompt"`
-	Resolution   string `json:"resolution"`
-	OutputFormat string `json:"output_format"`
-	FPS          int    `json:"fps"`
-	// Добавьте другие параметры, если ваша модель их поддерживает (например, duration, seed)
+	Prompt         string  `json:"prompt"`
+	NegativePrompt string  `json:"negative_prompt,omitempty"`
+	Model          string  `json:"model,omitempty"`
+	Resolution     string  `json:"resolution"`
+	OutputFormat   string  `json:"output_format"`
+	FPS            int     `json:"fps"`
+	Duration       float64 `json:"duration,omitempty"` // Длительность сегмента в секундах
+	Seed           int64   `json:"seed,omitempty"`
+	GuidanceScale  float64 `json:"guidance_scale,omitempty"`
+	Steps          int     `json:"steps,omitempty"`
+	MotionStrength float64 `json:"motion_strength,omitempty"`
+	// ReferenceImage — локальный путь к референс-изображению (первый кадр или образ персонажа).
+	// Провайдеры сами передают его в нужном бэкенду виде: data URI, загрузкой файла и т.п.
+	ReferenceImage string `json:"reference_image,omitempty"`
 }
 
-// VideoGenerationResponse соответствует структуре ответа от вашей видео-нейросети.
+// VideoSegment описывает сгенерированный сегмент и точные параметры запроса,
+// по которым его можно воспроизвести.
+type VideoSegment struct {
+	Index       int                    `json:"index"`
+	Path        string                 `json:"path"`
+	Provider    string                 `json:"provider"`
+	Request     VideoGenerationRequest `json:"request"`
+	GeneratedAt time.Time              `json:"generated_at"`
+}
+
+// VideoGenerationResponse соответствует структуре JSON-ответа от вашей видео-нейросети.
+// Модель может вернуть видео одним из способов: URL, base64 или путь к файлу на сервере.
 type VideoGenerationResponse struct {
-	VideoURL string `json:"video_url"` // Предполагаем, что модель возвращает URL видео
-	// Или base64 кодированное видео, или путь к файлу на сервере
+	VideoURL    string `json:"video_url"`    // http(s)://, file:// или data: URI
+	VideoBase64 string `json:"video_base64"` // Видео, закодированное в base64
+	B64JSON     string `json:"b64_json"`     // То же в стиле OpenAI
+	VideoPath   string `json:"video_path"`   // Путь к файлу на общем с сервером томе
+	Checksum    string `json:"checksum"`     // Необязательная контрольная сумма для video_url, "sha256:<hex>"
 }
 
 // GenerateVideoSegment генерирует короткий видеофрагмент на основе заданного промпта.
-// Возвращает путь к сгенерированному видеофайлу.
-func (vg *VideoGenerator) GenerateVideoSegment(prompt string, segmentIndex int) (string, error) {
-	vg.Logger.Info("Запрос на генерацию видеофрагмента для промпта (сцена %d): %s", segmentIndex, prompt)
+// params: параметры генерации сцены; незаданные поля берутся из ai.video.params,
+// а нулевой seed заменяется случайным, чтобы его можно было сохранить для воспроизведения.
+// workDir: рабочая директория запуска, куда сохраняется сегмент.
+// Возвращает сегмент с путем к видеофайлу и фактически отправленным запросом.
+func (vg *VideoGenerator) GenerateVideoSegment(prompt string, segmentIndex int, params config.VideoParams, workDir string) (*VideoSegment, error) {
+	params = vg.Config.AI.Video.Params.Merge(params)
+	randomSeed := params.Seed == 0
+	if randomSeed {
+		params.Seed = rand.Int63n(1<<31-1) + 1
+	}
+	vg.Logger.Info("Запрос на генерацию видеофрагмента для промпта (сцена %d, %.1f с, seed %d): %s", segmentIndex, params.Duration, params.Seed, prompt)
 
 	requestBody := VideoGenerationRequest{
-		Prompt:       prompt,
-		Resolution:   vg.Config.AI.Video.Resolution,
-		OutputFormat: vg.Config.AI.Video.OutputFormat,
-		FPS:          vg.Config.AI.Video.FPS,
+		Prompt:         prompt,
+		NegativePrompt: params.NegativePrompt,
+		Model:          params.Model,
+		Resolution:     vg.Config.AI.Video.Resolution,
+		OutputFormat:   vg.Config.AI.Video.OutputFormat,
+		FPS:            vg.Config.AI.Video.FPS,
+		Duration:       params.Duration,
+		Seed:           params.Seed,
+		GuidanceScale:  params.GuidanceScale,
+		Steps:          params.Steps,
+		MotionStrength: params.MotionStrength,
 	}
-
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return "", fmt.Errorf("ошибка при маршалинге JSON запроса для видео: %w", err)
+	if vg.Config.AI.Video.Continuity.ImageToVideo {
+		requestBody.ReferenceImage = params.ReferenceImage
+	} else if params.ReferenceImage != "" {
+		vg.Logger.Warn("Бэкенд не поддерживает image-to-video (ai.video.continuity.image_to_video), референс %s не передается", params.ReferenceImage)
 	}
 
-	req, err := http.NewRequest("POST", vg.Endpoint, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return "", fmt.Errorf("ошибка создания HTTP запроса для видео: %w", err)
+	videoPath := filepath.Join(workDir, fmt.Sprintf("segment_%d.%s", segmentIndex, vg.Config.AI.Video.OutputFormat))
+	for _, provider := range vg.Providers {
+		if segment := vg.cachedSegment(provider.Name(), requestBody, randomSeed, segmentIndex, videoPath); segment != nil {
+			return segment, nil
+		}
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+vg.APIKey) // Если требуется аутентификация
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("ошибка при отправке запроса к видео нейросети: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("получен некорректный статус от видео нейросети: %d - %s", resp.StatusCode, string(bodyBytes))
+	// Бэкенды пробуются по порядку; отключенные после серии отказов пропускаются
+	var errs []error
+	for _, provider := range vg.Providers {
+		name := provider.Name()
+		if ok, until := vg.Health.Available(name); !ok {
+			vg.Logger.Warn("Бэкенд %s временно отключен до %s, пропускаем", name, until.Format("15:04:05"))
+			errs = append(errs, fmt.Errorf("провайдер %s временно отключен", name))
+			continue
+		}
+
+		err := vg.generateWith(provider, requestBody, videoPath)
+		if err != nil {
+			vg.Logger.Warn("Бэкенд %s не сгенерировал сцену %d: %v", name, segmentIndex, err)
+			errs = append(errs, fmt.Errorf("провайдер %s: %w", name, err))
+			if vg.Health.RecordFailure(name) {
+				vg.Logger.Warn("Бэкенд %s отключен на %s после %d отказов подряд", name, vg.Health.Cooldown, vg.Health.MaxFailures)
+			}
+			continue
+		}
+		vg.Health.RecordSuccess(name)
+		vg.cacheSegment(name, requestBody, randomSeed, videoPath)
+
+		vg.Logger.Info("Видеофрагмент для сцены %d сгенерирован бэкендом %s и сохранен: %s", segmentIndex, name, videoPath)
+		return &VideoSegment{
+			Index:       segmentIndex,
+			Path:        videoPath,
+			Provider:    name,
+			Request:     requestBody,
+			GeneratedAt: time.Now(),
+		}, nil
 	}
+	return nil, fmt.Errorf("ни один видеобэкенд не сгенерировал сцену %d: %w", segmentIndex, errors.Join(errs...))
+}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+// generateWith отправляет запрос одному бэкенду и сохраняет результат в videoPath:
+// скачивает, декодирует или копирует с общего тома.
+func (vg *VideoGenerator) generateWith(provider VideoProvider, requestBody VideoGenerationRequest, videoPath string) error {
+	result, err := provider.Generate(requestBody)
 	if err != nil {
-		return "", fmt.Errorf("ошибка при чтении ответа от видео нейросети: %w", err)
+		return err
 	}
-
-	var responseData VideoGenerationResponse
-	err = json.Unmarshal(bodyBytes, &responseData)
-	if err != nil {
-		return "", fmt.Errorf("ошибка при демаршалинге JSON ответа видео: %w\nОтвет: %s", err, string(bodyBytes))
+	if err := saveVideoResult(result, videoPath, vg.Config.AI.Video.SharedVolume, vg.Downloader, vg.Logger); err != nil {
+		return fmt.Errorf("ошибка при сохранении видео: %w", err)
 	}
+	return nil
+}
 
-	if responseData.VideoURL == "" {
-		return "", fmt.Errorf("видео URL не найден в ответе от видео нейросети")
+// segmentCacheKey вычисляет ключ кэша сегмента. Случайный seed в ключ не входит, иначе
+// повторный запуск никогда не попадал бы в кэш; референс-изображение учитывается по содержимому.
+func segmentCacheKey(provider string, req VideoGenerationRequest, randomSeed bool) string {
+	if randomSeed {
+		req.Seed = 0
 	}
-
-	// Скачиваем видео по URL
-	videoPath := filepath.Join("temp_videos", fmt.Sprintf("segment_%d.%s", segmentIndex, vg.Config.AI.Video.OutputFormat))
-	err = downloadFile(responseData.VideoURL, videoPath, vg.Logger)
-	if err != nil {
-		return "", fmt.Errorf("ошибка при скачивании видео: %w", err)
+	reference := ""
+	if req.ReferenceImage != "" {
+		reference = cache.FileKey(req.ReferenceImage)
+		req.ReferenceImage = ""
 	}
-
-	vg.Logger.Info("Видеофрагмент для сцены %d сгенерирован и сохранен: %s", segmentIndex, videoPath)
-	return videoPath, nil
+	return cache.Key("video", provider, req, reference)
 }
 
-// downloadFile скачивает файл с заданного URL и сохраняет его по указанному пути.
-func downloadFile(url, filepath string, logger *utils.Logger) error {
-	logger.Info("Скачивание файла: %s в %s", url, filepath)
-
-	err := os.MkdirAll(filepath[:strings.LastIndex(filepath, "/")], os.ModePerm)
-	if err != nil && !os.IsExist(err) {
-		return fmt.Errorf("не удалось создать директорию: %w", err)
+// cachedSegment копирует сегмент из кэша в videoPath. Вместе с видео хранится фактический запрос,
+// чтобы в *_params.json попал seed, с которым сегмент был сгенерирован.
+func (vg *VideoGenerator) cachedSegment(provider string, req VideoGenerationRequest, randomSeed bool, segmentIndex int, videoPath string) *VideoSegment {
+	key := segmentCacheKey(provider, req, randomSeed)
+	meta, ok := vg.Cache.Get(key + "-request")
+	if !ok {
+		return nil
 	}
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("ошибка при HTTP GET запросе для скачивания: %w", err)
+	var cachedRequest VideoGenerationRequest
+	if err := json.Unmarshal(meta, &cachedRequest); err != nil {
+		return nil
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("получен некорректный статус при скачивании: %d", resp.StatusCode)
+	if !vg.Cache.GetFile(key, videoPath) {
+		return nil
 	}
-
-	out, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("ошибка создания файла для сохранения видео: %w", err)
+	cachedRequest.ReferenceImage = req.ReferenceImage
+	vg.Logger.Info("Видеофрагмент для сцены %d взят из кэша (бэкенд %s, seed %d): %s", segmentIndex, provider, cachedRequest.Seed, videoPath)
+	return &VideoSegment{
+		Index:       segmentIndex,
+		Path:        videoPath,
+		Provider:    provider,
+		Request:     cachedRequest,
+		GeneratedAt: time.Now(),
 	}
-	defer out.Close()
+}
 
-	_, err = io.Copy(out, resp.Body)
+// cacheSegment сохраняет сгенерированный сегмент и его запрос в кэш.
+func (vg *VideoGenerator) cacheSegment(provider string, req VideoGenerationRequest, randomSeed bool, videoPath string) {
+	if vg.Cache == nil {
+		return
+	}
+	meta, err := json.Marshal(req)
 	if err != nil {
-		return fmt.Errorf("ошибка записи скачанного файла: %w", err)
+		return
 	}
-	return nil
+	key := segmentCacheKey(provider, req, randomSeed)
+	vg.Cache.PutFile(key, videoPath)
+	vg.Cache.Put(key+"-request", meta)
 }
@@ -0,0 +1,76 @@
+// internal/ai/video_generic.go
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"ai-content-gen/pkg/utils"
+)
+
+// GenericVideoProvider работает с простой JSON-схемой: запрос VideoGenerationRequest,
+// ответ VideoGenerationResponse либо само видео.
+type GenericVideoProvider struct {
+	Endpoint string
+	APIKey   string
+	// MaxSizeMB ограничивает тело ответа (download.max_size_mb); 0 — без ограничения.
+	MaxSizeMB int64
+	Logger    *utils.Logger
+}
+
+// NewGenericVideoProvider создает новый экземпляр GenericVideoProvider.
+func NewGenericVideoProvider(endpoint, apiKey string, maxSizeMB int64, logger *utils.Logger) *GenericVideoProvider {
+	return &GenericVideoProvider{
+		Endpoint:  endpoint,
+		APIKey:    apiKey,
+		MaxSizeMB: maxSizeMB,
+		Logger:    logger,
+	}
+}
+
+// Name возвращает имя провайдера.
+func (p *GenericVideoProvider) Name() string {
+	return "generic"
+}
+
+// Generate отправляет синхронный запрос на генерацию. Ответ может быть JSON (URL, base64 или путь
+// на общем томе), бинарным видео или multipart-сообщением с видеочастью.
+func (p *GenericVideoProvider) Generate(requestBody VideoGenerationRequest) (*VideoResult, error) {
+	// Референс передается в JSON как data URI вместо локального пути
+	if requestBody.ReferenceImage != "" {
+		dataURI, err := imageDataURI(requestBody.ReferenceImage)
+		if err != nil {
+			return nil, err
+		}
+		requestBody.ReferenceImage = dataURI
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при маршалинге JSON запроса для видео: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", p.Endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания HTTP запроса для видео: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.APIKey) // Если требуется аутентификация
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при отправке запроса к видео нейросети: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("получен некорректный статус от видео нейросети: %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return readVideoResponse(resp.Header.Get("Content-Type"), resp.Body, p.MaxSizeMB)
+}
@@ -0,0 +1,57 @@
+// internal/ai/video_provider.go
+package ai
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+// VideoResult описывает результат генерации, полученный от провайдера.
+// Заполняется ровно одно из полей URL, Data, FilePath.
+type VideoResult struct {
+	URL      string // Адрес видео: http(s)://, file:// или data: URI
+	Data     []byte // Содержимое видео, полученное прямо в ответе
+	FilePath string // Путь к файлу на сервере модели (общий том)
+	Checksum string // Необязательная контрольная сумма скачиваемого по URL файла
+	// ContentTypes — допустимые типы содержимого при скачивании по URL; пусто — только video/*.
+	ContentTypes []string
+}
+
+// VideoProvider абстрагирует API конкретного бэкенда видеомодели.
+// Реализация отправляет запрос, дожидается завершения генерации и сообщает, где взять результат.
+type VideoProvider interface {
+	Name() string
+	Generate(req VideoGenerationRequest) (*VideoResult, error)
+}
+
+// NewVideoProvider создает адаптер для провайдера, указанного в ai.video.provider.
+// Пустое значение означает универсальную JSON-схему (generic).
+func NewVideoProvider(provider, endpoint, apiKey string, cfg *config.AppConfig, logger *utils.Logger) VideoProvider {
+	switch provider {
+	case config.VideoProviderComfyUI:
+		return NewComfyUIProvider(endpoint, cfg.AI.Video.ComfyUI, logger)
+	case config.VideoProviderReplicate:
+		return NewReplicateProvider(endpoint, apiKey, cfg.AI.Video.Replicate, logger)
+	default:
+		return NewGenericVideoProvider(endpoint, apiKey, cfg.Download.MaxSizeMB, logger)
+	}
+}
+
+// imageDataURI читает изображение и кодирует его в data URI для передачи в JSON.
+func imageDataURI(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("не удалось прочитать референс-изображение %s: %w", path, err)
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = "image/png"
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
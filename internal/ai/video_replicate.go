@@ -0,0 +1,180 @@
+// internal/ai/video_replicate.go
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+// ReplicateProvider работает с API предсказаний в стиле Replicate:
+// POST /predictions (или /models/{owner}/{name}/predictions), затем опрос urls.get.
+type ReplicateProvider struct {
+	BaseURL string
+	APIKey  string
+	Config  config.ReplicateConfig
+	Client  *http.Client
+	Logger  *utils.Logger
+}
+
+// NewReplicateProvider создает новый экземпляр ReplicateProvider.
+// baseURL: корень API, например https://api.replicate.com/v1.
+func NewReplicateProvider(baseURL, apiKey string, cfg config.ReplicateConfig, logger *utils.Logger) *ReplicateProvider {
+	return &ReplicateProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+		Config:  cfg,
+		Client:  &http.Client{Timeout: 60 * time.Second},
+		Logger:  logger,
+	}
+}
+
+// Name возвращает имя провайдера.
+func (p *ReplicateProvider) Name() string {
+	return "replicate"
+}
+
+// replicatePrediction — объект предсказания.
+type replicatePrediction struct {
+	ID     string          `json:"id"`
+	Status string          `json:"status"` // starting, processing, succeeded, failed, canceled
+	Output json.RawMessage `json:"output"`
+	Error  interface{}     `json:"error"`
+	URLs   struct {
+		Get string `json:"get"`
+	} `json:"urls"`
+}
+
+// Generate создает предсказание и ждет его завершения.
+func (p *ReplicateProvider) Generate(req VideoGenerationRequest) (*VideoResult, error) {
+	input := map[string]interface{}{}
+	for k, v := range p.Config.Input {
+		input[k] = v
+	}
+	// Значения из конфигурации могут содержать плейсхолдеры {{prompt}} и т.д.
+	input = fillTemplate(input, requestTemplateVars(req)).(map[string]interface{})
+	if _, ok := input["prompt"]; !ok {
+		input["prompt"] = req.Prompt
+	}
+	if _, ok := input["fps"]; !ok && req.FPS > 0 {
+		input["fps"] = req.FPS
+	}
+	setDefault := func(key string, value interface{}, isSet bool) {
+		if _, ok := input[key]; !ok && isSet {
+			input[key] = value
+		}
+	}
+	setDefault("duration", req.Duration, req.Duration > 0)
+	setDefault("negative_prompt", req.NegativePrompt, req.NegativePrompt != "")
+	setDefault("seed", req.Seed, req.Seed != 0)
+	setDefault("guidance_scale", req.GuidanceScale, req.GuidanceScale > 0)
+	setDefault("num_inference_steps", req.Steps, req.Steps > 0)
+	setDefault("motion_strength", req.MotionStrength, req.MotionStrength > 0)
+	if _, ok := input["image"]; !ok && req.ReferenceImage != "" {
+		dataURI, err := imageDataURI(req.ReferenceImage)
+		if err != nil {
+			return nil, err
+		}
+		input["image"] = dataURI
+	}
+
+	endpoint := p.BaseURL + "/predictions"
+	body := map[string]interface{}{"input": input}
+	switch {
+	case p.Config.Version != "":
+		body["version"] = p.Config.Version
+	case p.Config.Model != "":
+		endpoint = p.BaseURL + "/models/" + p.Config.Model + "/predictions"
+	default:
+		return nil, fmt.Errorf("для провайдера replicate не задан ни version, ни model")
+	}
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка маршалинга запроса предсказания: %w", err)
+	}
+	httpReq, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса предсказания: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	resp, err := p.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания предсказания: %w", err)
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("получен некорректный статус при создании предсказания: %d - %s", resp.StatusCode, string(respBody))
+	}
+
+	var prediction replicatePrediction
+	if err := json.Unmarshal(respBody, &prediction); err != nil {
+		return nil, fmt.Errorf("ошибка разбора предсказания: %w\nОтвет: %s", err, string(respBody))
+	}
+	p.Logger.Info("Создано предсказание %s (статус %s)", prediction.ID, prediction.Status)
+
+	final, err := p.waitForPrediction(&prediction)
+	if err != nil {
+		return nil, err
+	}
+
+	videoURL, err := predictionOutputURL(final.Output)
+	if err != nil {
+		return nil, err
+	}
+	return &VideoResult{URL: videoURL}, nil
+}
+
+// waitForPrediction опрашивает предсказание до конечного статуса или таймаута.
+func (p *ReplicateProvider) waitForPrediction(prediction *replicatePrediction) (*replicatePrediction, error) {
+	interval, timeout := pollSettings(p.Config.PollInterval, p.Config.Timeout)
+	deadline := time.Now().Add(timeout)
+	getURL := prediction.URLs.Get
+	if getURL == "" {
+		getURL = p.BaseURL + "/predictions/" + prediction.ID
+	}
+	headers := map[string]string{"Authorization": "Bearer " + p.APIKey}
+
+	for {
+		switch prediction.Status {
+		case "succeeded":
+			return prediction, nil
+		case "failed", "canceled":
+			return nil, fmt.Errorf("предсказание %s завершилось со статусом %s: %v", prediction.ID, prediction.Status, prediction.Error)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("истекло время ожидания предсказания %s (%s)", prediction.ID, timeout)
+		}
+
+		time.Sleep(interval)
+		var next replicatePrediction
+		if err := getJSON(p.Client, getURL, headers, &next); err != nil {
+			p.Logger.Warn("Ошибка опроса предсказания %s: %v", prediction.ID, err)
+			continue
+		}
+		prediction = &next
+	}
+}
+
+// predictionOutputURL извлекает URL видео из поля output: строки или массива строк (берется последний элемент).
+func predictionOutputURL(output json.RawMessage) (string, error) {
+	var single string
+	if err := json.Unmarshal(output, &single); err == nil && single != "" {
+		return single, nil
+	}
+	var list []string
+	if err := json.Unmarshal(output, &list); err == nil && len(list) > 0 {
+		return list[len(list)-1], nil
+	}
+	return "", fmt.Errorf("не удалось найти URL видео в output предсказания: %s", string(output))
+}
@@ -0,0 +1,256 @@
+// internal/ai/video_result.go
+package ai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/download"
+	"ai-content-gen/pkg/utils"
+)
+
+// readVideoResponse разбирает ответ видеомодели в зависимости от Content-Type:
+// бинарное видео (video/*, application/octet-stream), multipart с видеочастью или JSON VideoGenerationResponse.
+// Тело читается не больше maxSizeMB (download.max_size_mb), как и видео, скачиваемое по URL.
+func readVideoResponse(contentType string, body io.Reader, maxSizeMB int64) (*VideoResult, error) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	body = download.LimitReader(body, maxSizeMB)
+
+	switch {
+	case strings.HasPrefix(mediaType, "video/") || mediaType == "application/octet-stream":
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения бинарного ответа видео: %w", err)
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("получен пустой бинарный ответ видео")
+		}
+		return &VideoResult{Data: data}, nil
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return readMultipartVideo(body, params["boundary"])
+	}
+
+	bodyBytes, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении ответа от видео нейросети: %w", err)
+	}
+
+	var responseData VideoGenerationResponse
+	if err := json.Unmarshal(bodyBytes, &responseData); err != nil {
+		return nil, fmt.Errorf("ошибка при демаршалинге JSON ответа видео: %w\nОтвет: %s", err, string(bodyBytes))
+	}
+	return resultFromResponse(responseData)
+}
+
+// resultFromResponse выбирает заполненное поле JSON-ответа.
+func resultFromResponse(response VideoGenerationResponse) (*VideoResult, error) {
+	switch {
+	case response.VideoURL != "":
+		return &VideoResult{URL: response.VideoURL, Checksum: response.Checksum}, nil
+	case response.VideoBase64 != "" || response.B64JSON != "":
+		encoded := response.VideoBase64
+		if encoded == "" {
+			encoded = response.B64JSON
+		}
+		data, err := decodeBase64Video(encoded)
+		if err != nil {
+			return nil, err
+		}
+		return &VideoResult{Data: data}, nil
+	case response.VideoPath != "":
+		return &VideoResult{FilePath: response.VideoPath}, nil
+	}
+	return nil, fmt.Errorf("ответ видео нейросети не содержит ни video_url, ни base64, ни video_path")
+}
+
+// readMultipartVideo ищет в multipart-ответе часть с видео.
+// Если видеочасти нет, используется JSON-часть со ссылкой на результат.
+func readMultipartVideo(body io.Reader, boundary string) (*VideoResult, error) {
+	if boundary == "" {
+		return nil, fmt.Errorf("в multipart-ответе не указан boundary")
+	}
+
+	reader := multipart.NewReader(body, boundary)
+	var fallback *VideoResult
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения multipart-ответа: %w", err)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		data, err := io.ReadAll(part)
+		part.Close()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения части multipart-ответа: %w", err)
+		}
+
+		switch {
+		case strings.HasPrefix(partType, "video/") || isVideoFile(part.FileName()) ||
+			(partType == "application/octet-stream" && len(data) > 0):
+			return &VideoResult{Data: data}, nil
+		case partType == "application/json" && fallback == nil:
+			var response VideoGenerationResponse
+			if json.Unmarshal(data, &response) == nil {
+				fallback, _ = resultFromResponse(response)
+			}
+		}
+	}
+
+	if fallback != nil {
+		return fallback, nil
+	}
+	return nil, fmt.Errorf("в multipart-ответе не найдено видео")
+}
+
+// decodeBase64Video декодирует видео из base64, в том числе в виде data: URI
+// и в вариантах кодировки без выравнивания или URL-safe.
+func decodeBase64Video(encoded string) ([]byte, error) {
+	if strings.HasPrefix(encoded, "data:") {
+		if comma := strings.Index(encoded, ","); comma >= 0 {
+			encoded = encoded[comma+1:]
+		}
+	}
+	encoded = strings.Join(strings.Fields(encoded), "")
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if data, err := encoding.DecodeString(encoded); err == nil && len(data) > 0 {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("не удалось декодировать видео из base64")
+}
+
+// saveVideoResult сохраняет результат генерации в outputPath: декодирует встроенные данные,
+// копирует файл с общего тома или скачивает по URL.
+func saveVideoResult(result *VideoResult, outputPath string, volume config.SharedVolumeConfig, downloader *download.Downloader, logger *utils.Logger) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return fmt.Errorf("не удалось создать директорию: %w", err)
+	}
+
+	switch {
+	case len(result.Data) > 0:
+		logger.Info("Видео получено в теле ответа (%d байт), сохраняем в %s", len(result.Data), outputPath)
+		return writeFileAtomic(outputPath, bytes.NewReader(result.Data))
+	case result.FilePath != "":
+		sourcePath, err := resolveSharedPath(result.FilePath, volume)
+		if err != nil {
+			return err
+		}
+		return copyLocalFile(sourcePath, outputPath, logger)
+	case strings.HasPrefix(result.URL, "data:"):
+		data, err := decodeBase64Video(result.URL)
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(outputPath, bytes.NewReader(data))
+	case strings.HasPrefix(result.URL, "file://"):
+		parsed, err := url.Parse(result.URL)
+		if err != nil {
+			return fmt.Errorf("некорректный file:// URL %s: %w", result.URL, err)
+		}
+		sourcePath, err := resolveSharedPath(parsed.Path, volume)
+		if err != nil {
+			return err
+		}
+		return copyLocalFile(sourcePath, outputPath, logger)
+	case result.URL != "":
+		contentTypes := result.ContentTypes
+		if len(contentTypes) == 0 {
+			contentTypes = []string{"video/"}
+		}
+		return downloader.Download(download.Request{
+			URL:          result.URL,
+			Path:         outputPath,
+			ContentTypes: contentTypes,
+			Checksum:     result.Checksum,
+		})
+	}
+	return fmt.Errorf("провайдер не вернул ни данных, ни пути, ни URL видео")
+}
+
+// resolveSharedPath переводит путь на сервере модели в локальный путь общего тома.
+// Принимаются только пути внутри RemotePrefix, а результат с учетом ".." и символических ссылок
+// должен остаться внутри LocalPrefix: иначе сервер модели мог бы подсунуть в ролик любой локальный файл.
+func resolveSharedPath(remotePath string, volume config.SharedVolumeConfig) (string, error) {
+	if volume.RemotePrefix == "" || volume.LocalPrefix == "" {
+		return "", fmt.Errorf("модель вернула путь %s, но общий том не настроен (ai.video.shared_volume)", remotePath)
+	}
+	rel, ok := relativeTo(path.Clean(volume.RemotePrefix), path.Clean(remotePath), "/")
+	if !ok {
+		return "", fmt.Errorf("путь %s вне общего тома %s", remotePath, volume.RemotePrefix)
+	}
+
+	localRoot, err := filepath.EvalSymlinks(volume.LocalPrefix)
+	if err != nil {
+		return "", fmt.Errorf("общий том %s недоступен: %w", volume.LocalPrefix, err)
+	}
+	localPath, err := filepath.EvalSymlinks(filepath.Join(localRoot, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", fmt.Errorf("не удалось открыть файл на общем томе: %w", err)
+	}
+	if _, ok := relativeTo(localRoot, localPath, string(filepath.Separator)); !ok {
+		return "", fmt.Errorf("путь %s ведет за пределы общего тома %s", remotePath, volume.LocalPrefix)
+	}
+	return localPath, nil
+}
+
+// relativeTo возвращает путь target относительно очищенного base, если target лежит внутри base.
+func relativeTo(base, target, separator string) (string, bool) {
+	if target == base {
+		return ".", true
+	}
+	if !strings.HasSuffix(base, separator) {
+		base += separator
+	}
+	return strings.CutPrefix(target, base)
+}
+
+// copyLocalFile копирует файл с общего тома в outputPath.
+func copyLocalFile(sourcePath, outputPath string, logger *utils.Logger) error {
+	logger.Info("Копирование видео с общего тома: %s в %s", sourcePath, outputPath)
+
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть файл на общем томе: %w", err)
+	}
+	defer source.Close()
+
+	return writeFileAtomic(outputPath, source)
+}
+
+// writeFileAtomic записывает данные во временный файл рядом с path и переименовывает его,
+// чтобы при ошибке не оставалось недописанного файла.
+func writeFileAtomic(path string, r io.Reader) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.part")
+	if err != nil {
+		return fmt.Errorf("ошибка создания временного файла: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("ошибка переименования временного файла: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+// internal/api/dashboard.go
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// webFiles — веб-панель, встроенная в бинарник: статические файлы без сборки, которые работают через API.
+//
+//go:embed web
+var webFiles embed.FS
+
+// dashboard отдает веб-панель по адресу /ui/.
+func dashboard() http.Handler {
+	files, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err) // Каталог web встраивается при сборке и есть всегда
+	}
+	return http.StripPrefix("/ui/", http.FileServerFS(files))
+}
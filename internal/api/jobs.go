@@ -0,0 +1,85 @@
+// internal/api/jobs.go
+package api
+
+import (
+	"time"
+
+	"ai-content-gen/internal/jobs"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/internal/workspace"
+)
+
+// Job — задание в ответах API.
+type Job struct {
+	ID      string       `json:"id"`
+	Status  string       `json:"status"` // queued, running, awaiting_review, completed, rejected, canceled или dead
+	Request jobs.Request `json:"request"`
+
+	RunID string `json:"run_id,omitempty"`
+	Stage string `json:"stage,omitempty"`
+	// Step и Steps — сцены этапа или, на этапах монтажа, секунды, обработанные FFmpeg (см. pipeline.Progress)
+	Step  int `json:"step,omitempty"`
+	Steps int `json:"steps,omitempty"`
+	// Stages — история этапов из манифеста запуска; только в ответе по одному заданию
+	Stages []workspace.StageRecord `json:"stages,omitempty"`
+	// Metadata — заголовки, описания и теги для платформ; появляются перед публикацией
+	Metadata []workspace.PublishMetadata `json:"metadata,omitempty"`
+	// Review — решение проверяющего, если задание проходит проверку
+	Review *workspace.ReviewRecord `json:"review,omitempty"`
+	// RetryStage — этап, с которого повторяется запуск; пусто — с упавшего этапа
+	RetryStage string `json:"retry_stage,omitempty"`
+	// RetryScenes — сцены, которые повтор генерирует заново; остальные сегменты сохраняются
+	RetryScenes []int  `json:"retry_scenes,omitempty"`
+	Attempts    int    `json:"attempts"`
+	MaxAttempts int    `json:"max_attempts"`
+	Worker      string `json:"worker,omitempty"`
+
+	Idea  string `json:"idea,omitempty"`
+	Video string `json:"video,omitempty"` // Путь API для скачивания ролика
+	Error string `json:"error,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// newJob переводит задание из очереди в представление API.
+func newJob(job *store.Job) Job {
+	view := Job{
+		ID:     job.ID,
+		Status: job.Status,
+		Request: jobs.Request{
+			Channel:   job.Channel,
+			Topic:     job.Topic,
+			Style:     job.Style,
+			Platforms: job.Platforms,
+			PublishAt: job.PublishAt,
+			Review:    &job.Review,
+		},
+		RunID:       job.RunID,
+		Stage:       job.Stage,
+		Step:        job.Step,
+		Steps:       job.Steps,
+		RetryStage:  job.RetryStage,
+		RetryScenes: job.RetryScenes,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		Worker:      job.Worker,
+		Idea:        job.Idea,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   optionalTime(job.StartedAt),
+		FinishedAt:  optionalTime(job.FinishedAt),
+	}
+	if job.VideoPath != "" {
+		view.Video = "/api/jobs/" + job.ID + "/video"
+	}
+	return view
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,300 @@
+// internal/api/server.go
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ai-content-gen/internal/jobs"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/internal/workspace"
+	"ai-content-gen/pkg/utils"
+)
+
+const (
+	// maxBodySize ограничивает размер тела запроса к API.
+	maxBodySize = 1 << 20
+	// defaultReviewer — имя проверяющего, если клиент его не передал.
+	defaultReviewer = "api"
+)
+
+// Server — HTTP API для заданий на генерацию роликов.
+//
+//	POST  /api/jobs                 — новое задание {channel, topic, style, platforms, publish_at, review}
+//	GET   /api/jobs?status=dead     — список заданий
+//	GET   /api/jobs/{id}            — статус задания, ход этапов, метаданные и решение проверяющего
+//	GET   /api/jobs/{id}/manifest   — манифест запуска
+//	GET   /api/jobs/{id}/video      — готовый ролик
+//	POST  /api/jobs/{id}/cancel     — отмена
+//	POST  /api/jobs/{id}/retry      — повтор с упавшего этапа или с {stage}
+//	POST  /api/jobs/{id}/approve    — одобрить ролик к публикации {reviewer, comment}
+//	POST  /api/jobs/{id}/reject     — отклонить ролик {reviewer, comment}
+//	POST  /api/jobs/{id}/publish    — одобрить и опубликовать сразу, без отложенной публикации {reviewer, comment}
+//	POST  /api/jobs/{id}/regenerate — заново сгенерировать сцены {scenes: [2]} и пересобрать ролик
+//	PATCH /api/jobs/{id}/metadata   — правка [{platform, title, description, tags}] до одобрения
+//
+// По адресу /ui/ сервер отдает веб-панель для просмотра и проверки роликов; она обращается к тому же API.
+type Server struct {
+	Jobs   *jobs.Queue
+	Token  string // Если задан, запросы должны передавать его в заголовке Authorization: Bearer
+	Logger *utils.Logger
+}
+
+// NewServer создает HTTP API поверх очереди заданий.
+func NewServer(queue *jobs.Queue, token string, logger *utils.Logger) *Server {
+	return &Server{Jobs: queue, Token: token, Logger: logger}
+}
+
+// Handler возвращает обработчик всех маршрутов API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/jobs", s.submit)
+	mux.HandleFunc("GET /api/jobs", s.list)
+	mux.HandleFunc("GET /api/jobs/{id}", s.get)
+	mux.HandleFunc("GET /api/jobs/{id}/manifest", s.manifest)
+	mux.HandleFunc("GET /api/jobs/{id}/video", s.video)
+	mux.HandleFunc("POST /api/jobs/{id}/cancel", s.cancel)
+	mux.HandleFunc("POST /api/jobs/{id}/retry", s.retry)
+	mux.HandleFunc("POST /api/jobs/{id}/approve", s.review)
+	mux.HandleFunc("POST /api/jobs/{id}/reject", s.review)
+	mux.HandleFunc("POST /api/jobs/{id}/publish", s.review)
+	mux.HandleFunc("POST /api/jobs/{id}/regenerate", s.regenerate)
+	mux.HandleFunc("PATCH /api/jobs/{id}/metadata", s.editMetadata)
+	mux.Handle("GET /ui/", dashboard())
+	mux.Handle("GET /{$}", http.RedirectHandler("/ui/", http.StatusFound))
+	return s.authorize(mux)
+}
+
+// authorize проверяет токен доступа к API, если он задан. Файлы веб-панели отдаются без токена:
+// панель спрашивает его у пользователя и передает в запросах к API.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Token != "" && strings.HasPrefix(r.URL.Path, "/api/") {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+				writeError(w, http.StatusUnauthorized, fmt.Errorf("нужен токен доступа"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	var req jobs.Request
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	job, err := s.Jobs.Submit(req)
+	if err != nil {
+		s.writeJobError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, s.details(job))
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	list, err := s.Jobs.List(r.URL.Query().Get("status"), limit)
+	if err != nil {
+		s.writeJobError(w, err)
+		return
+	}
+	views := make([]Job, 0, len(list))
+	for _, job := range list {
+		views = append(views, newJob(&job))
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Jobs.Get(r.PathValue("id"))
+	if err != nil {
+		s.writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.details(job))
+}
+
+func (s *Server) manifest(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Jobs.Get(r.PathValue("id"))
+	if err != nil {
+		s.writeJobError(w, err)
+		return
+	}
+	if job.Dir == "" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("запуск задания еще не начался"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	http.ServeFile(w, r, workspace.ManifestPath(job.Dir))
+}
+
+func (s *Server) video(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Jobs.Get(r.PathValue("id"))
+	if err != nil {
+		s.writeJobError(w, err)
+		return
+	}
+	if job.VideoPath == "" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("ролик задания еще не готов"))
+		return
+	}
+	if _, err := os.Stat(job.VideoPath); err != nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("файл ролика недоступен: %w", err))
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filepath.Base(job.VideoPath)))
+	http.ServeFile(w, r, job.VideoPath)
+}
+
+func (s *Server) cancel(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Jobs.Cancel(r.PathValue("id"))
+	if err != nil {
+		s.writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, s.details(job))
+}
+
+func (s *Server) retry(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Stage string `json:"stage"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	job, err := s.Jobs.Retry(r.PathValue("id"), body.Stage)
+	if err != nil {
+		s.writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, s.details(job))
+}
+
+// review одобряет, отклоняет или сразу публикует ролик задания, ждущего проверки.
+func (s *Server) review(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reviewer string `json:"reviewer"`
+		Comment  string `json:"comment"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if body.Reviewer == "" {
+		body.Reviewer = defaultReviewer
+	}
+	decide := s.Jobs.Approve
+	switch path.Base(r.URL.Path) {
+	case "reject":
+		decide = s.Jobs.Reject
+	case "publish":
+		decide = s.Jobs.Publish
+	}
+	job, err := decide(r.PathValue("id"), body.Reviewer, body.Comment)
+	if err != nil {
+		s.writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.details(job))
+}
+
+func (s *Server) regenerate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Scenes []int `json:"scenes"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	job, err := s.Jobs.Regenerate(r.PathValue("id"), body.Scenes)
+	if err != nil {
+		s.writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, s.details(job))
+}
+
+func (s *Server) editMetadata(w http.ResponseWriter, r *http.Request) {
+	var edits []workspace.PublishMetadata
+	if err := decodeBody(r, &edits); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	job, err := s.Jobs.EditMetadata(r.PathValue("id"), edits)
+	if err != nil {
+		s.writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.details(job))
+}
+
+// details дополняет задание этапами, метаданными публикации и решением проверяющего
+// из манифеста запуска (он сохраняется после каждого этапа).
+func (s *Server) details(job *store.Job) Job {
+	view := newJob(job)
+	if job.Dir == "" {
+		return view
+	}
+	manifest, err := workspace.ReadManifest(job.Dir)
+	if err != nil {
+		s.Logger.Warn("Этапы задания %s недоступны: %v", job.ID, err)
+		return view
+	}
+	view.Stages = manifest.Stages
+	view.Metadata = manifest.Metadata
+	view.Review = manifest.Review
+	return view
+}
+
+// writeJobError выбирает HTTP-статус по ошибке очереди заданий.
+func (s *Server) writeJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrInvalidJob):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, jobs.ErrJobNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, jobs.ErrJobState):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, jobs.ErrQueueFull):
+		writeError(w, http.StatusServiceUnavailable, err)
+	default:
+		s.Logger.Error("Ошибка API: %v", err)
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// decodeBody разбирает JSON-тело запроса; пустое тело допускается.
+func decodeBody(r *http.Request, v any) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("некорректное тело запроса: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,336 @@
+// Веб-панель заданий: список, сценарий и промпты сцен, предпросмотр ролика и решения проверяющего.
+// Работает только через HTTP API; токен и имя проверяющего хранятся в localStorage браузера.
+"use strict";
+
+const REFRESH_INTERVAL = 5000;
+
+// Статусы, в которых доступны действия с заданием (см. jobs.Queue).
+const REVIEWABLE = ["awaiting_review"];
+const REGENERATABLE = ["awaiting_review", "rejected", "canceled", "dead"];
+
+// Этапы, шаги которых — сцены; на этапах монтажа шаги — секунды, обработанные FFmpeg (см. pipeline.Progress).
+const SCENE_STAGES = ["prompts", "segments"];
+
+const STATUS_NAMES = {
+  queued: "в очереди",
+  running: "выполняется",
+  awaiting_review: "ждет проверки",
+  completed: "выполнено",
+  rejected: "отклонено",
+  canceled: "отменено",
+  dead: "исчерпало попытки",
+};
+
+const $ = (id) => document.getElementById(id);
+
+let selectedID = "";
+let selectedJob = null;
+let previewURL = "";
+let previewKey = ""; // Задание и время его завершения: после пересборки ролик загружается заново
+
+// api выполняет запрос к API с токеном и возвращает разобранный JSON.
+async function api(method, path, body) {
+  const headers = {};
+  const token = localStorage.getItem("token");
+  if (token) {
+    headers.Authorization = "Bearer " + token;
+  }
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+  const response = await fetch(path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  const data = await response.json().catch(() => null);
+  if (!response.ok) {
+    throw new Error((data && data.error) || response.status + " " + response.statusText);
+  }
+  return data;
+}
+
+function cell(row, text, className) {
+  const td = row.insertCell();
+  td.textContent = text == null ? "" : text;
+  if (className) {
+    td.className = className;
+  }
+  return td;
+}
+
+function statusBadge(status) {
+  const span = document.createElement("span");
+  span.className = "status " + status;
+  span.textContent = STATUS_NAMES[status] || status;
+  return span;
+}
+
+function formatTime(value) {
+  return value ? new Date(value).toLocaleString("ru-RU", { dateStyle: "short", timeStyle: "short" }) : "";
+}
+
+function stageText(job) {
+  if (!job.stage) {
+    return "";
+  }
+  if (!job.steps) {
+    return job.stage;
+  }
+  const unit = SCENE_STAGES.includes(job.stage) ? "" : " с";
+  return `${job.stage} ${job.step}/${job.steps}${unit}`;
+}
+
+async function loadJobs() {
+  const status = $("status").value;
+  const query = status ? "?status=" + encodeURIComponent(status) : "";
+  try {
+    const jobs = await api("GET", "/api/jobs" + query);
+    $("list-error").hidden = true;
+    renderJobs(jobs);
+  } catch (err) {
+    $("list-error").textContent = err.message;
+    $("list-error").hidden = false;
+  }
+}
+
+function renderJobs(jobs) {
+  const tbody = $("jobs");
+  tbody.replaceChildren();
+  for (const job of jobs) {
+    const row = tbody.insertRow();
+    row.dataset.id = job.id;
+    row.classList.toggle("selected", job.id === selectedID);
+    cell(row, formatTime(job.created_at));
+    cell(row, job.request.channel);
+    cell(row, "").append(statusBadge(job.status));
+    cell(row, stageText(job));
+    cell(row, job.idea || job.request.topic);
+    row.addEventListener("click", () => selectJob(job.id));
+  }
+  if (jobs.length === 0) {
+    cell(tbody.insertRow(), "Заданий нет", "muted").colSpan = 5;
+  }
+}
+
+async function selectJob(id) {
+  selectedID = id;
+  for (const row of $("jobs").rows) {
+    row.classList.toggle("selected", row.dataset.id === id);
+  }
+  await loadDetails();
+}
+
+async function loadDetails() {
+  if (!selectedID) {
+    return;
+  }
+  try {
+    const job = await api("GET", "/api/jobs/" + selectedID);
+    const manifest = job.run_id ? await api("GET", `/api/jobs/${selectedID}/manifest`).catch(() => null) : null;
+    selectedJob = job;
+    renderDetails(job, manifest);
+  } catch (err) {
+    $("job-error").textContent = err.message;
+    $("job-error").hidden = false;
+  }
+}
+
+function renderDetails(job, manifest) {
+  $("details").hidden = false;
+  $("job-title").textContent = job.idea || job.request.topic || job.id;
+
+  const status = $("job-status");
+  status.replaceChildren(statusBadge(job.status), ` ${job.id}, этап ${stageText(job) || "—"}, попытка ${job.attempts} из ${job.max_attempts}`);
+  $("job-error").textContent = job.error || "";
+  $("job-error").hidden = !job.error;
+
+  const review = job.review;
+  $("job-review").textContent = review && review.reviewer
+    ? `Проверка: ${review.status}, ${review.reviewer} (${formatTime(review.updated_at)})${review.comment ? ": " + review.comment : ""}`
+    : "";
+
+  const reviewable = REVIEWABLE.includes(job.status);
+  for (const id of ["approve", "publish", "reject"]) {
+    $(id).disabled = !reviewable;
+  }
+
+  renderPreview(job);
+  renderScript(manifest);
+  renderScenes(job, manifest);
+  renderMetadata(job, reviewable);
+}
+
+// renderPreview загружает ролик с токеном в заголовке: тег video сам передать его не умеет.
+async function renderPreview(job) {
+  const video = $("preview");
+  const note = $("preview-note");
+  if (!job.video) {
+    video.hidden = true;
+    note.textContent = "Ролик еще не собран.";
+    note.hidden = false;
+    return;
+  }
+  const key = job.id + "@" + job.finished_at;
+  if (previewKey === key && previewURL) {
+    return;
+  }
+  note.textContent = "Загрузка ролика...";
+  note.hidden = false;
+  try {
+    const token = localStorage.getItem("token");
+    const response = await fetch(job.video, { headers: token ? { Authorization: "Bearer " + token } : {} });
+    if (!response.ok) {
+      throw new Error("ролик недоступен: " + response.status);
+    }
+    const blob = await response.blob();
+    if (previewURL) {
+      URL.revokeObjectURL(previewURL);
+    }
+    previewURL = URL.createObjectURL(blob);
+    previewKey = key;
+    video.src = previewURL;
+    video.hidden = false;
+    note.hidden = true;
+  } catch (err) {
+    video.hidden = true;
+    note.textContent = err.message;
+  }
+}
+
+function renderScript(manifest) {
+  const dl = $("script");
+  dl.replaceChildren();
+  const script = manifest && manifest.script;
+  if (!script) {
+    dl.textContent = "Сценарий еще не готов.";
+    return;
+  }
+  for (const [name, value] of [["Идея", script.Idea], ["Хук", script.Hook], ["Призыв", script.CallToAction]]) {
+    if (!value) {
+      continue;
+    }
+    const dt = document.createElement("dt");
+    dt.textContent = name;
+    const dd = document.createElement("dd");
+    dd.textContent = value;
+    dl.append(dt, dd);
+  }
+}
+
+function renderScenes(job, manifest) {
+  const tbody = $("scenes");
+  tbody.replaceChildren();
+  const prompts = (manifest && manifest.prompts) || [];
+  const segments = new Map(((manifest && manifest.segments) || []).map((segment) => [segment.index, segment]));
+  const regeneratable = REGENERATABLE.includes(job.status);
+  prompts.forEach((prompt, i) => {
+    const scene = i + 1;
+    const row = tbody.insertRow();
+    cell(row, scene);
+    const description = [prompt.scene.Description];
+    if (prompt.scene.Duration) {
+      description.push(`${prompt.scene.Duration.toFixed(1)} с`);
+    }
+    if (prompt.scene.Overlay) {
+      description.push("Надпись: " + prompt.scene.Overlay);
+    }
+    cell(row, description.join("\n"), "prompt");
+    cell(row, prompt.prompt, "prompt");
+    const segment = segments.get(scene);
+    cell(row, segment ? `${segment.provider}, seed ${segment.request.seed || "—"}` : "нет", segment ? "" : "muted");
+    const button = document.createElement("button");
+    button.className = "secondary";
+    button.textContent = "Перегенерировать";
+    button.disabled = !regeneratable;
+    button.addEventListener("click", () => act(`/api/jobs/${job.id}/regenerate`, { scenes: [scene] },
+      `Сгенерировать сцену ${scene} заново и пересобрать ролик?`));
+    cell(row, "").append(button);
+  });
+  if (prompts.length === 0) {
+    cell(tbody.insertRow(), "Промпты сцен еще не готовы.", "muted").colSpan = 5;
+  }
+}
+
+function renderMetadata(job, editable) {
+  const container = $("metadata");
+  container.replaceChildren();
+  const metadata = job.metadata || [];
+  for (const md of metadata) {
+    const form = $("metadata-form").content.firstElementChild.cloneNode(true);
+    form.querySelector("h4").textContent = md.platform;
+    for (const name of ["title", "description", "tags"]) {
+      form.elements[name].value = md[name];
+      form.elements[name].disabled = !editable;
+    }
+    form.querySelector("button").disabled = !editable;
+    form.addEventListener("submit", (event) => {
+      event.preventDefault();
+      act(`/api/jobs/${job.id}/metadata`, [{
+        platform: md.platform,
+        title: form.elements.title.value,
+        description: form.elements.description.value,
+        tags: form.elements.tags.value,
+      }], "", "PATCH");
+    });
+    container.append(form);
+  }
+  if (metadata.length === 0) {
+    container.textContent = "Метаданные появятся, когда ролик будет готов к публикации.";
+  }
+}
+
+// act выполняет действие с заданием и обновляет панель.
+async function act(path, body, question, method = "POST") {
+  if (question && !confirm(question)) {
+    return;
+  }
+  try {
+    await api(method, path, body);
+    await Promise.all([loadJobs(), loadDetails()]);
+  } catch (err) {
+    alert(err.message);
+  }
+}
+
+function review(action) {
+  const job = selectedJob;
+  if (!job) {
+    return;
+  }
+  const reviewer = $("reviewer").value.trim();
+  let comment = "";
+  if (action === "reject") {
+    comment = prompt("Причина отклонения");
+    if (!comment) {
+      return;
+    }
+  }
+  const question = action === "publish" ? "Опубликовать ролик сразу?" : "";
+  act(`/api/jobs/${job.id}/${action}`, { reviewer, comment }, question);
+}
+
+function init() {
+  for (const id of ["token", "reviewer"]) {
+    const input = $(id);
+    input.value = localStorage.getItem(id) || "";
+    input.addEventListener("change", () => {
+      localStorage.setItem(id, input.value.trim());
+      loadJobs();
+    });
+  }
+  $("status").addEventListener("change", loadJobs);
+  for (const button of document.querySelectorAll("[data-action]")) {
+    button.addEventListener("click", () => review(button.dataset.action));
+  }
+  loadJobs();
+  setInterval(() => {
+    loadJobs();
+    // Подробности обновляются, пока задание в работе; у остановленного они не меняются, а правки метаданных не затираются
+    if (selectedJob && ["queued", "running"].includes(selectedJob.status)) {
+      loadDetails();
+    }
+  }, REFRESH_INTERVAL);
+}
+
+init();
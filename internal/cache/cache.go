@@ -0,0 +1,301 @@
+// internal/cache/cache.go
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+const (
+	defaultDir = ".cache/ai-content-gen"
+	defaultTTL = 7 * 24 * time.Hour
+	// usedSuffix — пустой файл рядом с записью, время изменения которого — время последнего использования.
+	// Время изменения самой записи остается временем ее создания, по нему считается TTL.
+	usedSuffix = ".used"
+)
+
+// Cache — локальный кэш с адресацией по содержимому: ключ — хэш всех входных данных запроса
+// к модели, значение — ответ модели или сгенерированный файл. Записи старше TTL считаются
+// устаревшими, а при превышении MaxSize удаляются самые давно использованные.
+// Все методы безопасны для nil и для выключенного кэша.
+type Cache struct {
+	Dir     string
+	TTL     time.Duration
+	MaxSize int64 // В байтах; 0 — без ограничения
+	Logger  *utils.Logger
+
+	mu sync.Mutex
+}
+
+// New создает кэш по настройкам. Если кэш выключен, возвращает nil.
+func New(cfg config.CacheConfig, logger *utils.Logger) *Cache {
+	if !cfg.Enabled {
+		return nil
+	}
+	dir, ttl := cfg.Dir, cfg.TTL
+	if dir == "" {
+		dir = defaultDir
+	}
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &Cache{
+		Dir:     dir,
+		TTL:     ttl,
+		MaxSize: cfg.MaxSizeMB << 20,
+		Logger:  logger,
+	}
+}
+
+// Key вычисляет ключ кэша по произвольному набору значений (провайдер, модель, промпт, параметры).
+// Значения сериализуются в JSON, поэтому порядок полей структур стабилен.
+func Key(parts ...interface{}) string {
+	h := sha256.New()
+	for _, part := range parts {
+		data, err := json.Marshal(part)
+		if err != nil {
+			data = []byte(fmt.Sprintf("%v", part))
+		}
+		h.Write(data)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FileKey возвращает хэш содержимого файла для включения в ключ (например, референс-изображения).
+// Для недоступного файла возвращает пустую строку.
+func FileKey(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get возвращает сохраненное значение, если запись есть и не устарела.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	path, ok := c.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Put сохраняет значение под ключом.
+func (c *Cache) Put(key string, data []byte) {
+	if c == nil {
+		return
+	}
+	c.store(key, func(tmp *os.File) error {
+		_, err := tmp.Write(data)
+		return err
+	})
+}
+
+// GetFile копирует сохраненный файл в outputPath. Возвращает false, если записи нет.
+func (c *Cache) GetFile(key, outputPath string) bool {
+	path, ok := c.lookup(key)
+	if !ok {
+		return false
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return false
+	}
+	if err := copyFile(path, outputPath); err != nil {
+		c.Logger.Warn("Не удалось взять файл из кэша %s: %v", path, err)
+		return false
+	}
+	return true
+}
+
+// PutFile сохраняет копию файла под ключом.
+func (c *Cache) PutFile(key, path string) {
+	if c == nil {
+		return
+	}
+	c.store(key, func(tmp *os.File) error {
+		source, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		_, err = io.Copy(tmp, source)
+		return err
+	})
+}
+
+// lookup находит актуальную запись и отмечает ее как использованную.
+func (c *Cache) lookup(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	path := c.entryPath(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	if time.Since(info.ModTime()) > c.TTL {
+		removeEntry(path)
+		return "", false
+	}
+	// Использование отмечается рядом с записью, чтобы не продлевать ей жизнь сверх TTL
+	touch(path + usedSuffix)
+	return path, true
+}
+
+// store атомарно записывает запись и при необходимости освобождает место.
+func (c *Cache) store(key string, write func(tmp *os.File) error) {
+	path := c.entryPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		c.Logger.Warn("Не удалось создать директорию кэша: %v", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		c.Logger.Warn("Не удалось создать запись кэша: %v", err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		c.Logger.Warn("Не удалось записать в кэш: %v", err)
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		c.Logger.Warn("Не удалось записать в кэш: %v", err)
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		c.Logger.Warn("Не удалось сохранить запись кэша: %v", err)
+		return
+	}
+	os.Remove(path + usedSuffix)
+	c.evict()
+}
+
+// entryPath раскладывает записи по подкаталогам по первым символам ключа.
+func (c *Cache) entryPath(key string) string {
+	return filepath.Join(c.Dir, key[:2], key)
+}
+
+// evict удаляет устаревшие записи, а затем самые давно использованные, пока кэш больше MaxSize.
+func (c *Cache) evict() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	type entry struct {
+		path     string
+		size     int64
+		lastUsed time.Time
+	}
+	var entries []*entry
+	byPath := make(map[string]*entry)
+	used := make(map[string]time.Time)
+	var total int64
+	filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) == ".tmp" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if entryPath, ok := strings.CutSuffix(path, usedSuffix); ok {
+			used[entryPath] = info.ModTime()
+			return nil
+		}
+		if time.Since(info.ModTime()) > c.TTL {
+			removeEntry(path)
+			return nil
+		}
+		e := &entry{path, info.Size(), info.ModTime()}
+		entries = append(entries, e)
+		byPath[path] = e
+		total += info.Size()
+		return nil
+	})
+	for path, lastUsed := range used {
+		e, ok := byPath[path]
+		if !ok {
+			// Отметка осталась от удаленной записи
+			os.Remove(path + usedSuffix)
+			continue
+		}
+		if lastUsed.After(e.lastUsed) {
+			e.lastUsed = lastUsed
+		}
+	}
+
+	if c.MaxSize <= 0 || total <= c.MaxSize {
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].lastUsed.Before(entries[j].lastUsed) })
+	for _, e := range entries {
+		if total <= c.MaxSize {
+			break
+		}
+		if err := removeEntry(e.path); err == nil {
+			total -= e.size
+		}
+	}
+	c.Logger.Info("Кэш сокращен до %d МБ", total>>20)
+}
+
+// touch создает пустой файл, если его нет, и ставит ему текущее время изменения.
+func touch(path string) {
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); os.IsNotExist(err) {
+		os.WriteFile(path, nil, 0o644)
+	}
+}
+
+// removeEntry удаляет запись вместе с отметкой о ее использовании.
+func removeEntry(path string) error {
+	os.Remove(path + usedSuffix)
+	return os.Remove(path)
+}
+
+// copyFile копирует файл через временный, чтобы прерванное копирование не оставило обрезанный результат.
+func copyFile(src, dst string) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.part")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, source); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
@@ -20,23 +21,422 @@ type AppConfig struct {
 			Temperature       float64 `yaml:"temperature"`
 		} `yaml:"text"`
 		Video struct {
-			OutputFormat string `yaml:"output_format"`
-			Resolution   string `yaml:"resolution"`
-			FPS          int    `yaml:"fps"`
+			OutputFormat string             `yaml:"output_format"`
+			Resolution   string             `yaml:"resolution"`
+			FPS          int                `yaml:"fps"`
+			Duration     DurationConfig     `yaml:"duration"`
+			Provider     string             `yaml:"provider"` // generic, comfyui или replicate
+			ComfyUI      ComfyUIConfig      `yaml:"comfyui"`
+			Replicate    ReplicateConfig    `yaml:"replicate"`
+			SharedVolume SharedVolumeConfig `yaml:"shared_volume"`
+			Params       VideoParams        `yaml:"params"` // Параметры генерации по умолчанию
+			Continuity   ContinuityConfig   `yaml:"continuity"`
+			Slideshow    SlideshowConfig    `yaml:"slideshow"`
+			Fallback     FallbackConfig     `yaml:"fallback"`
 		} `yaml:"video"`
+		Image struct {
+			Model string `yaml:"model"`
+			Size  string `yaml:"size"`
+		} `yaml:"image"`
+		Embeddings EmbeddingsConfig `yaml:"embeddings"`
 	} `yaml:"ai"`
-	// Здесь больше нет секции Platforms, так как ключи будут в .env
+	Workspace  WorkspaceConfig           `yaml:"workspace"`
+	Store      StoreConfig               `yaml:"store"`
+	Scheduler  SchedulerConfig           `yaml:"scheduler"`
+	API        APIConfig                 `yaml:"api"`
+	Jobs       JobsConfig                `yaml:"jobs"`
+	Review     ReviewConfig              `yaml:"review"`
+	Download   DownloadConfig            `yaml:"download"`
+	Cache      CacheConfig               `yaml:"cache"`
+	Thumbnail  ThumbnailConfig           `yaml:"thumbnail"`
+	Overlays   OverlayConfig             `yaml:"overlays"`
+	Channels   []ChannelConfig           `yaml:"channels"`
+	Renditions []RenditionConfig         `yaml:"renditions"`
+	Platforms  map[string]PlatformConfig `yaml:"platforms"` // Ключи API по-прежнему в .env, здесь только несекретные настройки
+}
+
+// DurationConfig задает ограничения по длительности итогового ролика и его сцен (в секундах).
+type DurationConfig struct {
+	Target     float64 `yaml:"target"`      // Желаемая общая длительность ролика
+	Max        float64 `yaml:"max"`         // Жесткий предел платформы (например, 60 для Shorts)
+	MinScene   float64 `yaml:"min_scene"`   // Минимальная длительность одной сцены
+	MaxScene   float64 `yaml:"max_scene"`   // Максимальная длительность, которую поддерживает видеомодель
+	MaxSpeedup float64 `yaml:"max_speedup"` // Допустимое ускорение при превышении Max; сверх него видео обрезается
+}
+
+// WorkspaceConfig задает, где создаются рабочие директории запусков.
+type WorkspaceConfig struct {
+	Root          string `yaml:"root"`            // Каждый запуск получает <root>/<run id>
+	KeepTemp      bool   `yaml:"keep_temp"`       // Не удалять промежуточные файлы (для отладки)
+	MaxNameLength int    `yaml:"max_name_length"` // Предельная длина имени файла результата в символах
+}
+
+// EmbeddingsConfig задает проверку идей на повторы по сходству эмбеддингов.
+type EmbeddingsConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	Model        string  `yaml:"model"`
+	Threshold    float64 `yaml:"threshold"`     // Косинусное сходство, начиная с которого идея считается повтором
+	MaxAttempts  int     `yaml:"max_attempts"`  // Сколько раз генерировать идею, прежде чем принять повтор
+	CompareLimit int     `yaml:"compare_limit"` // Со сколькими последними идеями канала сравнивать
+}
+
+// StoreConfig задает базу истории запусков.
+type StoreConfig struct {
+	Path string `yaml:"path"` // Файл SQLite; пусто — data/history.db
+}
+
+// SchedulerConfig задает общие настройки режима демона (serve).
+type SchedulerConfig struct {
+	// MissedWindow — насколько поздно можно выполнить пропущенный слот расписания (например, после перезапуска).
+	// Более старые слоты пропускаются; 0 — 30 минут.
+	MissedWindow time.Duration `yaml:"missed_window"`
+}
+
+// APIConfig задает HTTP API для заданий на генерацию роликов (команда api).
+type APIConfig struct {
+	Addr    string `yaml:"addr"`    // Адрес сервера; пусто — :8090
+	Workers int    `yaml:"workers"` // Обработчиков заданий в процессе API; 0 — задания выполняют только процессы worker
+}
+
+// JobsConfig задает очередь заданий в базе истории и ее обработчики (команды worker и api).
+type JobsConfig struct {
+	Workers      int           `yaml:"workers"`       // Обработчиков в одном процессе worker; 0 — 1
+	Lease        time.Duration `yaml:"lease"`         // Аренда задания: без продления за это время задание вернется в очередь; 0 — 2 минуты
+	Heartbeat    time.Duration `yaml:"heartbeat"`     // Как часто продлевать аренду; 0 — треть lease
+	PollInterval time.Duration `yaml:"poll_interval"` // Как часто проверять очередь, если она пуста; 0 — 5 секунд
+	MaxAttempts  int           `yaml:"max_attempts"`  // Попыток, после которых задание уходит в dead; 0 — 3
+	RetryDelay   time.Duration `yaml:"retry_delay"`   // Пауза перед повтором упавшего задания, растет с каждой попыткой; 0 — 1 минута
+	MaxQueued    int           `yaml:"max_queued"`    // Сколько заданий может ждать в очереди; 0 — 100
+}
+
+// ReviewConfig задает проверку роликов человеком перед публикацией.
+type ReviewConfig struct {
+	// Enabled — задания по умолчанию останавливаются перед публикацией до одобрения; задание может переопределить это полем review
+	Enabled bool `yaml:"enabled"`
+}
+
+// DownloadConfig задает ограничения при скачивании результатов нейросетей.
+type DownloadConfig struct {
+	Timeout          time.Duration `yaml:"timeout"`           // Предельное время скачивания одного файла
+	Retries          int           `yaml:"retries"`           // Попыток с докачкой при сетевых ошибках
+	MaxSizeMB        int64         `yaml:"max_size_mb"`       // 0 — без ограничения
+	ProgressInterval time.Duration `yaml:"progress_interval"` // Как часто писать прогресс в лог
+}
+
+// CacheConfig задает локальный кэш ответов текстовой модели и сгенерированных сегментов.
+type CacheConfig struct {
+	Enabled   bool          `yaml:"enabled"` // Флаг --no-cache выключает кэш на один запуск
+	Dir       string        `yaml:"dir"`
+	TTL       time.Duration `yaml:"ttl"`
+	MaxSizeMB int64         `yaml:"max_size_mb"` // 0 — без ограничения
+}
+
+// ThumbnailConfig задает способ получения обложки и оформление заголовка на ней.
+type ThumbnailConfig struct {
+	Enabled        bool    `yaml:"enabled"`
+	Mode           string  `yaml:"mode"` // "frame" — лучший кадр из видео, "ai" — генерация изображения
+	Width          int     `yaml:"width"`
+	Height         int     `yaml:"height"`
+	SceneThreshold float64 `yaml:"scene_threshold"` // Порог смены сцены для отбора кадров-кандидатов
+	MaxCandidates  int     `yaml:"max_candidates"`
+	FontFile       string  `yaml:"font_file"` // Шрифт с поддержкой кириллицы
+	FontSize       int     `yaml:"font_size"`
+	FontColor      string  `yaml:"font_color"`
+}
+
+// OverlayConfig задает оформление текстовых надписей поверх видео.
+type OverlayConfig struct {
+	Enabled     bool               `yaml:"enabled"`
+	FontFile    string             `yaml:"font_file"` // Пусто — автоматический выбор шрифта с кириллицей
+	FontColor   string             `yaml:"font_color"`
+	BorderColor string             `yaml:"border_color"`
+	SafeZone    SafeZoneConfig     `yaml:"safe_zone"`
+	Hook        OverlayStyleConfig `yaml:"hook"`  // Цепляющая фраза в первые секунды
+	Scene       OverlayStyleConfig `yaml:"scene"` // Подписи к сценам
+	CTA         OverlayStyleConfig `yaml:"cta"`   // Призыв к действию в конце ролика
+}
+
+// SafeZoneConfig задает поля (доля высоты/ширины кадра), которые перекрывает интерфейс платформы.
+type SafeZoneConfig struct {
+	Top    float64 `yaml:"top"`
+	Bottom float64 `yaml:"bottom"`
+	Side   float64 `yaml:"side"`
+}
+
+// OverlayStyleConfig задает положение, анимацию и размер для одного вида надписей.
+type OverlayStyleConfig struct {
+	Position  string  `yaml:"position"`  // top, center, bottom
+	Animation string  `yaml:"animation"` // none, fade, pop
+	FontSize  int     `yaml:"font_size"`
+	Duration  float64 `yaml:"duration"` // Длительность показа в секундах (для hook и cta)
+}
+
+// ChannelConfig описывает канал публикации и его фирменное оформление.
+type ChannelConfig struct {
+	Name     string         `yaml:"name"`
+	Branding BrandingConfig `yaml:"branding"`
+	Topics   TopicsConfig   `yaml:"topics"`
+	Schedule ScheduleConfig `yaml:"schedule"`
+}
+
+// ScheduleConfig задает расписание публикаций канала в режиме демона.
+type ScheduleConfig struct {
+	Cron        []string      `yaml:"cron"`         // Cron-выражения (минута час день месяц день_недели) для запусков
+	Jitter      time.Duration `yaml:"jitter"`       // Случайная задержка запуска от 0 до Jitter, чтобы публикации не выходили ровно по часам
+	Timezone    string        `yaml:"timezone"`     // Часовой пояс расписания (например, Europe/Moscow); пусто — локальный
+	FetchTopics string        `yaml:"fetch_topics"` // Cron-выражение для сбора тем из источников; пусто — только когда очередь пуста
+}
+
+// Типы источников тем.
+const (
+	TopicSourceFeed = "feed" // RSS или Atom
+	TopicSourceCSV  = "csv"  // Локальный список тем
+)
+
+// TopicsConfig задает, откуда канал берет темы и как их отбирает.
+type TopicsConfig struct {
+	Default   string              `yaml:"default"` // Тема, если очередь пуста
+	Sources   []TopicSourceConfig `yaml:"sources"`
+	MinScore  float64             `yaml:"min_score"`  // Минимальная оценка текстовой модели (0-10) для попадания в очередь
+	MaxQueued int                 `yaml:"max_queued"` // Сколько лучших кандидатов ставить в очередь за один сбор
+	MaxAge    time.Duration       `yaml:"max_age"`    // Записи лент старше этого не рассматриваются; 0 — без ограничения
+}
+
+// TopicSourceConfig описывает один источник тем.
+type TopicSourceConfig struct {
+	Name  string `yaml:"name"`
+	Type  string `yaml:"type"`  // feed или csv
+	URL   string `yaml:"url"`   // Для feed
+	Path  string `yaml:"path"`  // Для csv: файл с колонками topic[,note]
+	Limit int    `yaml:"limit"` // Сколько последних записей брать из источника; 0 — 20
+}
+
+// BrandingConfig задает водяной знак, заставки и финальную карточку канала.
+type BrandingConfig struct {
+	Logo struct {
+		Path     string  `yaml:"path"`     // PNG с прозрачностью
+		Position string  `yaml:"position"` // top-left, top-right, bottom-left, bottom-right
+		Opacity  float64 `yaml:"opacity"`
+		Scale    float64 `yaml:"scale"` // Ширина логотипа как доля ширины кадра
+		Margin   int     `yaml:"margin"`
+	} `yaml:"logo"`
+	Intro   string `yaml:"intro"` // Видео, добавляемое в начало
+	Outro   string `yaml:"outro"` // Видео, добавляемое в конец
+	EndCard struct {
+		Image    string  `yaml:"image"`
+		Text     string  `yaml:"text"`
+		Duration float64 `yaml:"duration"`
+	} `yaml:"end_card"`
+}
+
+// Channel возвращает настройки канала по имени.
+// Пустое имя допустимо, если в конфигурации не более одного канала;
+// если каналы не описаны вовсе, возвращается канал "default" без оформления.
+func (c *AppConfig) Channel(name string) (*ChannelConfig, error) {
+	if name == "" {
+		switch len(c.Channels) {
+		case 0:
+			return &ChannelConfig{Name: "default"}, nil
+		case 1:
+			return &c.Channels[0], nil
+		default:
+			return nil, fmt.Errorf("в конфигурации несколько каналов, укажите нужный")
+		}
+	}
+	for i := range c.Channels {
+		if c.Channels[i].Name == name {
+			return &c.Channels[i], nil
+		}
+	}
+	return nil, fmt.Errorf("канал %q не найден в config.yaml", name)
+}
+
+// RenditionConfig описывает один вариант кодирования итогового ролика.
+type RenditionConfig struct {
+	Name         string `yaml:"name"`
+	Resolution   string `yaml:"resolution"`
+	Fit          string `yaml:"fit"` // crop — заполнить кадр с обрезкой, pad — вписать с полями
+	VideoCodec   string `yaml:"video_codec"`
+	Profile      string `yaml:"profile"`
+	Preset       string `yaml:"preset"`
+	VideoBitrate string `yaml:"video_bitrate"` // Если пусто, используется CRF
+	CRF          int    `yaml:"crf"`
+	AudioBitrate string `yaml:"audio_bitrate"`
+}
+
+// PlatformConfig содержит несекретные настройки публикации на платформе.
+type PlatformConfig struct {
+	Rendition string `yaml:"rendition"` // Имя варианта из renditions; пусто — основной файл
+	// PublishAt — часы пик в формате ЧЧ:ММ. Готовый ролик выходит в ближайшее из них;
+	// пусто — публикация сразу после генерации.
+	PublishAt []string `yaml:"publish_at"`
+	Timezone  string   `yaml:"timezone"` // Часовой пояс для publish_at; пусто — локальный
+}
+
+// minPublishLead — минимальный запас до отложенной публикации, чтобы платформа успела обработать ролик.
+const minPublishLead = 5 * time.Minute
+
+// NextPublishTime возвращает ближайшее время из publish_at не раньше чем через minPublishLead после now.
+// Возвращает нулевое время, если publish_at не задан.
+func (p PlatformConfig) NextPublishTime(now time.Time) (time.Time, error) {
+	if len(p.PublishAt) == 0 {
+		return time.Time{}, nil
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("некорректный часовой пояс публикации: %w", err)
+	}
+	earliest := now.Add(minPublishLead).In(loc)
+	var next time.Time
+	for _, value := range p.PublishAt {
+		clock, err := time.Parse("15:04", value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("некорректное время публикации %q, ожидается ЧЧ:ММ", value)
+		}
+		candidate := time.Date(earliest.Year(), earliest.Month(), earliest.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		if candidate.Before(earliest) {
+			candidate = candidate.AddDate(0, 0, 1)
+		}
+		if next.IsZero() || candidate.Before(next) {
+			next = candidate
+		}
+	}
+	return next, nil
+}
+
+// Поддерживаемые провайдеры видеомодели.
+const (
+	VideoProviderGeneric   = "generic"
+	VideoProviderComfyUI   = "comfyui"
+	VideoProviderReplicate = "replicate"
+)
+
+// Режимы слайд-шоу из неподвижных изображений.
+const (
+	SlideshowOff      = "off"      // Только видеомодель
+	SlideshowFallback = "fallback" // Слайд-шоу для сцен, которые видеомодель не смогла сгенерировать
+	SlideshowAlways   = "always"   // Ролик целиком из изображений, видеомодель не нужна
+)
+
+// Источники изображений для слайд-шоу.
+const (
+	SlideshowSourceImage  = "image"  // Нейросеть изображений (IMAGE_AI_ENDPOINT)
+	SlideshowSourceAssets = "assets" // Готовые изображения из локальной папки
+)
+
+// SlideshowConfig задает режим, в котором сцены собираются из неподвижных изображений
+// с эффектом Кена Бернса (медленное приближение и панорамирование).
+type SlideshowConfig struct {
+	Mode     string  `yaml:"mode"`      // off, fallback или always
+	Source   string  `yaml:"source"`    // image или assets
+	AssetDir string  `yaml:"asset_dir"` // Папка с изображениями; для source=image — запасной источник
+	Zoom     float64 `yaml:"zoom"`      // Итоговое увеличение кадра, например 1.2
+}
+
+// VideoParams — параметры генерации видеосегмента. Задаются глобально в ai.video.params
+// и могут переопределяться для отдельной сцены в сценарии. Нулевое значение означает "не задано".
+type VideoParams struct {
+	Model          string  `yaml:"model" json:"model,omitempty"`
+	Seed           int64   `yaml:"seed" json:"seed,omitempty"` // 0 — случайный seed (фактический сохраняется)
+	NegativePrompt string  `yaml:"negative_prompt" json:"negative_prompt,omitempty"`
+	Duration       float64 `yaml:"duration" json:"duration,omitempty"`
+	GuidanceScale  float64 `yaml:"guidance_scale" json:"guidance_scale,omitempty"`
+	Steps          int     `yaml:"steps" json:"steps,omitempty"`
+	MotionStrength float64 `yaml:"motion_strength" json:"motion_strength,omitempty"`
+	ReferenceImage string  `yaml:"reference_image" json:"reference_image,omitempty"` // Путь к изображению для image-to-video
+}
+
+// Merge возвращает параметры, в которых заданные (ненулевые) поля override заменяют значения p.
+func (p VideoParams) Merge(override VideoParams) VideoParams {
+	if override.Model != "" {
+		p.Model = override.Model
+	}
+	if override.Seed != 0 {
+		p.Seed = override.Seed
+	}
+	if override.NegativePrompt != "" {
+		p.NegativePrompt = override.NegativePrompt
+	}
+	if override.Duration != 0 {
+		p.Duration = override.Duration
+	}
+	if override.GuidanceScale != 0 {
+		p.GuidanceScale = override.GuidanceScale
+	}
+	if override.Steps != 0 {
+		p.Steps = override.Steps
+	}
+	if override.MotionStrength != 0 {
+		p.MotionStrength = override.MotionStrength
+	}
+	if override.ReferenceImage != "" {
+		p.ReferenceImage = override.ReferenceImage
+	}
+	return p
+}
+
+// FallbackConfig задает цепочку видеобэкендов, которые пробуются для каждой сцены по порядку.
+// Если список пуст, используется единственный бэкенд из ai.video.provider.
+type FallbackConfig struct {
+	Backends    []VideoBackendConfig `yaml:"backends"`
+	MaxFailures int                  `yaml:"max_failures"` // Подряд неудачных запросов до временного отключения бэкенда
+	Cooldown    time.Duration        `yaml:"cooldown"`     // На сколько бэкенд исключается из цепочки
+}
+
+// VideoBackendConfig описывает один видеобэкенд цепочки.
+type VideoBackendConfig struct {
+	Name      string `yaml:"name"`
+	Provider  string `yaml:"provider"`    // generic, comfyui или replicate
+	Endpoint  string `yaml:"endpoint"`    // Пусто — VIDEO_AI_ENDPOINT
+	APIKeyEnv string `yaml:"api_key_env"` // Переменная среды с ключом; пусто — VIDEO_AI_API_KEY
+	APIKey    string `yaml:"-"`           // Заполняется из APIKeyEnv при загрузке
+}
+
+// ContinuityConfig задает средства визуальной согласованности сцен.
+type ContinuityConfig struct {
+	StyleBible     bool `yaml:"style_bible"`      // Генерировать стилевую библию и вставлять ее в промпты сцен
+	ImageToVideo   bool `yaml:"image_to_video"`   // Бэкенд принимает референс-изображение
+	ChainLastFrame bool `yaml:"chain_last_frame"` // Передавать последний кадр предыдущего сегмента как референс
+}
+
+// SharedVolumeConfig описывает общий с сервером видеомодели том: пути в ответах модели,
+// начинающиеся с RemotePrefix, читаются локально из LocalPrefix. Пути вне тома отклоняются.
+type SharedVolumeConfig struct {
+	RemotePrefix string `yaml:"remote_prefix"`
+	LocalPrefix  string `yaml:"local_prefix"`
+}
+
+// ComfyUIConfig задает workflow и опрос для ComfyUI (/prompt + /history).
+type ComfyUIConfig struct {
+	Workflow     string        `yaml:"workflow"`    // Workflow в API-формате с плейсхолдерами {{prompt}}, {{width}} и т.д.
+	OutputNode   string        `yaml:"output_node"` // ID узла с результатом; пусто — первый узел с видео
+	PollInterval time.Duration `yaml:"poll_interval"`
+	Timeout      time.Duration `yaml:"timeout"`
+}
+
+// ReplicateConfig задает модель и опрос для API предсказаний в стиле Replicate.
+type ReplicateConfig struct {
+	Version      string                 `yaml:"version"` // ID версии модели
+	Model        string                 `yaml:"model"`   // Или owner/name для официальных моделей
+	Input        map[string]interface{} `yaml:"input"`   // Дополнительные входные параметры модели
+	PollInterval time.Duration          `yaml:"poll_interval"`
+	Timeout      time.Duration          `yaml:"timeout"`
 }
 
 // Config содержит все настройки для нашего бота, включая переменные среды и YAML.
 type Config struct {
-	AppName         string
-	YouTubeAPIKey   string
-	TikTokAPIKey    string // Теперь ключ TikTok тоже здесь, из .env
-	TextAIEndpoint  string
-	VideoAIEndpoint string
-	VideoAIAPIKey   string
-	App             *AppConfig // Ссылка на YAML-конфигурацию
+	AppName             string
+	YouTubeAPIKey       string
+	TikTokAPIKey        string // Теперь ключ TikTok тоже здесь, из .env
+	TextAIEndpoint      string
+	VideoAIEndpoint     string
+	VideoAIAPIKey       string
+	ImageAIEndpoint     string
+	ImageAIAPIKey       string
+	EmbeddingAIEndpoint string
+	EmbeddingAIAPIKey   string
+	APIToken            string     // Токен доступа к HTTP API; пусто — без проверки
+	App                 *AppConfig // Ссылка на YAML-конфигурацию
 }
 
 // LoadConfig загружает конфигурацию из переменных среды и YAML файла.
@@ -59,13 +459,18 @@ func LoadConfig() (*Config, error) {
 	}
 
 	cfg := &Config{
-		AppName:         getEnv("APP_NAME", "YouTube Shorts AI Bot"),
-		YouTubeAPIKey:   os.Getenv("YOUTUBE_API_KEY"),
-		TikTokAPIKey:    os.Getenv("TIKTOK_API_KEY"), // Читаем ключ TikTok из .env
-		TextAIEndpoint:  getEnv("TEXT_AI_ENDPOINT", "http://10.66.66.5:8000/v1/chat/completions"),
-		VideoAIEndpoint: getEnv("VIDEO_AI_ENDPOINT", "http://10.66.66.5:8081/v1/video/generations"),
-		VideoAIAPIKey:   os.Getenv("VIDEO_AI_API_KEY"),
-		App:             &appCfg, // Сохраняем загруженную YAML-конфигурацию
+		AppName:             getEnv("APP_NAME", "YouTube Shorts AI Bot"),
+		YouTubeAPIKey:       os.Getenv("YOUTUBE_API_KEY"),
+		TikTokAPIKey:        os.Getenv("TIKTOK_API_KEY"), // Читаем ключ TikTok из .env
+		TextAIEndpoint:      getEnv("TEXT_AI_ENDPOINT", "http://10.66.66.5:8000/v1/chat/completions"),
+		VideoAIEndpoint:     getEnv("VIDEO_AI_ENDPOINT", "http://10.66.66.5:8081/v1/video/generations"),
+		VideoAIAPIKey:       os.Getenv("VIDEO_AI_API_KEY"),
+		ImageAIEndpoint:     getEnv("IMAGE_AI_ENDPOINT", "http://10.66.66.5:8082/v1/images/generations"),
+		ImageAIAPIKey:       os.Getenv("IMAGE_AI_API_KEY"),
+		EmbeddingAIEndpoint: getEnv("EMBEDDING_AI_ENDPOINT", "http://10.66.66.5:8000/v1/embeddings"),
+		EmbeddingAIAPIKey:   os.Getenv("EMBEDDING_AI_API_KEY"),
+		APIToken:            os.Getenv("API_TOKEN"),
+		App:                 &appCfg, // Сохраняем загруженную YAML-конфигурацию
 	}
 
 	// Базовые проверки, что ключи API и эндпоинты не пустые
@@ -81,13 +486,99 @@ func LoadConfig() (*Config, error) {
 	if cfg.VideoAIEndpoint == "" {
 		return nil, fmt.Errorf("VIDEO_AI_ENDPOINT не установлен")
 	}
-	if cfg.VideoAIAPIKey == "" {
-		return nil, fmt.Errorf("VIDEO_AI_API_KEY не установлен")
+	switch appCfg.AI.Video.Slideshow.Mode {
+	case "", SlideshowOff, SlideshowFallback, SlideshowAlways:
+	default:
+		return nil, fmt.Errorf("неизвестный режим слайд-шоу: %s", appCfg.AI.Video.Slideshow.Mode)
+	}
+	if appCfg.AI.Video.Slideshow.Mode != SlideshowOff && appCfg.AI.Video.Slideshow.Mode != "" &&
+		appCfg.AI.Video.Slideshow.Source == SlideshowSourceAssets && appCfg.AI.Video.Slideshow.AssetDir == "" {
+		return nil, fmt.Errorf("для слайд-шоу из готовых изображений не задан ai.video.slideshow.asset_dir")
+	}
+	// В режиме always видеомодель не используется, ее ключ не нужен
+	videoModelRequired := appCfg.AI.Video.Slideshow.Mode != SlideshowAlways
+
+	if len(appCfg.AI.Video.Fallback.Backends) == 0 {
+		if err := validateVideoProvider(appCfg.AI.Video.Provider, cfg.VideoAIAPIKey, "VIDEO_AI_API_KEY", &appCfg, videoModelRequired); err != nil {
+			return nil, err
+		}
+	}
+	for i := range appCfg.AI.Video.Fallback.Backends {
+		backend := &appCfg.AI.Video.Fallback.Backends[i]
+		if backend.Name == "" {
+			backend.Name = fmt.Sprintf("%s-%d", backend.Provider, i+1)
+		}
+		if backend.Endpoint == "" {
+			backend.Endpoint = cfg.VideoAIEndpoint
+		}
+		keyEnv := "VIDEO_AI_API_KEY"
+		if backend.APIKeyEnv != "" {
+			keyEnv = backend.APIKeyEnv
+		}
+		backend.APIKey = os.Getenv(keyEnv)
+		if err := validateVideoProvider(backend.Provider, backend.APIKey, keyEnv, &appCfg, videoModelRequired); err != nil {
+			return nil, fmt.Errorf("видеобэкенд %s: %w", backend.Name, err)
+		}
+	}
+	for name, platform := range appCfg.Platforms {
+		if _, err := platform.NextPublishTime(time.Now()); err != nil {
+			return nil, fmt.Errorf("платформа %s: %w", name, err)
+		}
+	}
+	for _, channel := range appCfg.Channels {
+		if err := validateTopicSources(channel.Topics.Sources); err != nil {
+			return nil, fmt.Errorf("канал %s: %w", channel.Name, err)
+		}
+		if _, err := time.LoadLocation(channel.Schedule.Timezone); err != nil {
+			return nil, fmt.Errorf("канал %s: некорректный часовой пояс расписания: %w", channel.Name, err)
+		}
+	}
+	// Аренда, которую не успевают продлить, истекает посреди работы, и задание выполняется дважды
+	if jobs := appCfg.Jobs; jobs.Lease > 0 && jobs.Heartbeat >= jobs.Lease {
+		return nil, fmt.Errorf("jobs.heartbeat (%s) должен быть меньше jobs.lease (%s)", jobs.Heartbeat, jobs.Lease)
 	}
 
 	return cfg, nil
 }
 
+// validateVideoProvider проверяет, что для провайдера видеомодели заданы ключ и настройки.
+// required=false отключает проверки, когда видеомодель не используется.
+func validateVideoProvider(provider, apiKey, keyEnv string, appCfg *AppConfig, required bool) error {
+	switch provider {
+	case "", VideoProviderGeneric, VideoProviderReplicate:
+		if required && apiKey == "" {
+			return fmt.Errorf("%s не установлен", keyEnv)
+		}
+	case VideoProviderComfyUI:
+		// Локальный ComfyUI обычно работает без ключа
+		if required && appCfg.AI.Video.ComfyUI.Workflow == "" {
+			return fmt.Errorf("для провайдера comfyui не задан ai.video.comfyui.workflow")
+		}
+	default:
+		return fmt.Errorf("неизвестный провайдер видеомодели: %s", provider)
+	}
+	return nil
+}
+
+// validateTopicSources проверяет, что у каждого источника тем задан тип и адрес.
+func validateTopicSources(sources []TopicSourceConfig) error {
+	for i, source := range sources {
+		switch source.Type {
+		case TopicSourceFeed:
+			if source.URL == "" {
+				return fmt.Errorf("у источника тем %d (%s) не задан url", i+1, source.Name)
+			}
+		case TopicSourceCSV:
+			if source.Path == "" {
+				return fmt.Errorf("у источника тем %d (%s) не задан path", i+1, source.Name)
+			}
+		default:
+			return fmt.Errorf("неизвестный тип источника тем %d (%s): %q", i+1, source.Name, source.Type)
+		}
+	}
+	return nil
+}
+
 // getEnv получает переменную среды или возвращает значение по умолчанию.
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
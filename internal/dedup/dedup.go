@@ -0,0 +1,80 @@
+// internal/dedup/dedup.go
+package dedup
+
+import (
+	"fmt"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/pkg/utils"
+)
+
+const defaultThreshold = 0.9
+
+// Checker сравнивает новую идею с прошлыми идеями канала по косинусному сходству эмбеддингов.
+type Checker struct {
+	Embeddings *ai.EmbeddingGenerator
+	Store      *store.Store
+	Config     config.EmbeddingsConfig
+	Logger     *utils.Logger
+}
+
+// Result — итог проверки идеи.
+type Result struct {
+	Vector     []float32 // Эмбеддинг проверенной идеи, сохраняется через Remember
+	Similarity float64   // Наибольшее сходство с прошлыми идеями
+	Match      string    // Самая похожая прошлая идея
+	Duplicate  bool      // Similarity не меньше порога
+}
+
+// NewChecker создает новый экземпляр Checker.
+func NewChecker(embeddings *ai.EmbeddingGenerator, history *store.Store, cfg config.EmbeddingsConfig, logger *utils.Logger) *Checker {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultThreshold
+	}
+	return &Checker{
+		Embeddings: embeddings,
+		Store:      history,
+		Config:     cfg,
+		Logger:     logger,
+	}
+}
+
+// Check вычисляет эмбеддинг идеи и ищет самую похожую из последних идей канала.
+func (c *Checker) Check(channel, idea string) (*Result, error) {
+	vector, err := c.Embeddings.Embed(idea)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить эмбеддинг идеи: %w", err)
+	}
+	result := &Result{Vector: vector}
+
+	past, err := c.Store.IdeaEmbeddings(channel, c.Config.Model, c.Config.CompareLimit)
+	if err != nil {
+		return result, err
+	}
+	for _, candidate := range past {
+		if similarity := ai.CosineSimilarity(vector, candidate.Vector); similarity > result.Similarity {
+			result.Similarity, result.Match = similarity, candidate.Idea
+		}
+	}
+	result.Duplicate = result.Similarity >= c.Config.Threshold
+	if result.Match != "" {
+		c.Logger.Info("Сходство идеи с прошлыми: %.3f (порог %.2f), ближайшая: %s", result.Similarity, c.Config.Threshold, result.Match)
+	}
+	return result, nil
+}
+
+// Remember сохраняет эмбеддинг принятой идеи, чтобы следующие запуски с ней сравнивались.
+func (c *Checker) Remember(runID, channel, idea string, result *Result) {
+	if result == nil || len(result.Vector) == 0 {
+		return
+	}
+	c.Store.SaveIdeaEmbedding(store.IdeaEmbedding{
+		RunID:   runID,
+		Channel: channel,
+		Idea:    idea,
+		Model:   c.Config.Model,
+		Vector:  result.Vector,
+	})
+}
@@ -0,0 +1,337 @@
+// internal/download/download.go
+package download
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+const (
+	defaultTimeout          = 10 * time.Minute
+	defaultRetries          = 3
+	defaultProgressInterval = 5 * time.Second
+)
+
+// Request описывает один файл для скачивания.
+type Request struct {
+	URL  string
+	Path string // Куда сохранить файл
+	// ContentTypes — допустимые префиксы Content-Type, например "video/".
+	// application/octet-stream и пустой заголовок допускаются всегда.
+	ContentTypes []string
+	// Checksum — ожидаемая контрольная сумма вида "sha256:<hex>" или "md5:<hex>";
+	// значение без префикса считается SHA-256. Пусто — не проверяется.
+	Checksum string
+}
+
+// Downloader скачивает файлы по HTTP: сначала во временный файл <path>.<хэш URL>.part,
+// который при обрыве докачивается запросом Range, и лишь после проверок
+// атомарно переименовывает его в итоговый путь.
+type Downloader struct {
+	Client *http.Client
+	Config config.DownloadConfig
+	Logger *utils.Logger
+}
+
+// NewDownloader создает новый экземпляр Downloader.
+// Нулевые значения настроек заменяются разумными значениями по умолчанию.
+func NewDownloader(cfg config.DownloadConfig, logger *utils.Logger) *Downloader {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Retries <= 0 {
+		cfg.Retries = defaultRetries
+	}
+	if cfg.ProgressInterval <= 0 {
+		cfg.ProgressInterval = defaultProgressInterval
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   15 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+	}
+	return &Downloader{
+		Client: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		Config: cfg,
+		Logger: logger,
+	}
+}
+
+// permanentError — ошибка, которую бессмысленно повторять (лимит размера, тип файла, 4xx).
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// LimitReader ограничивает чтение r лимитом download.max_size_mb, чтобы ответ, полученный не по URL,
+// был защищен так же, как скачивание. При превышении чтение возвращает постоянную ошибку;
+// при нулевом лимите r возвращается как есть.
+func LimitReader(r io.Reader, maxSizeMB int64) io.Reader {
+	if maxSizeMB <= 0 {
+		return r
+	}
+	maxSize := maxSizeMB << 20
+	return &limitedReader{r: &io.LimitedReader{R: r, N: maxSize + 1}, maxSize: maxSize}
+}
+
+// limitedReader читает на байт больше лимита, чтобы отличить превышение от ответа ровно в лимит.
+type limitedReader struct {
+	r       *io.LimitedReader
+	maxSize int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if l.r.N <= 0 {
+		return n, &permanentError{fmt.Errorf("ответ превысил лимит %d байт", l.maxSize)}
+	}
+	return n, err
+}
+
+// Download скачивает req.URL в req.Path. Сетевые ошибки и ответы 5xx повторяются
+// до Config.Retries раз, каждая попытка продолжает уже скачанную часть.
+// При ошибке недокачанная часть удаляется: в тот же путь может писать уже другой провайдер.
+func (d *Downloader) Download(req Request) error {
+	d.Logger.Info("Скачивание файла: %s в %s", req.URL, req.Path)
+
+	if err := os.MkdirAll(filepath.Dir(req.Path), os.ModePerm); err != nil {
+		return fmt.Errorf("не удалось создать директорию: %w", err)
+	}
+	partPath := partFile(req)
+
+	var err error
+	for attempt := 1; attempt <= d.Config.Retries; attempt++ {
+		err = d.fetch(req, partPath)
+		if err == nil {
+			break
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt == d.Config.Retries {
+			break
+		}
+		delay := time.Duration(attempt) * 2 * time.Second
+		d.Logger.Warn("Скачивание %s прервано (попытка %d из %d), повтор через %s: %v", req.URL, attempt, d.Config.Retries, delay, err)
+		time.Sleep(delay)
+	}
+	if err != nil {
+		os.Remove(partPath)
+		return err
+	}
+
+	if req.Checksum != "" {
+		if err := verifyChecksum(partPath, req.Checksum); err != nil {
+			os.Remove(partPath)
+			return err
+		}
+	}
+	if err := os.Rename(partPath, req.Path); err != nil {
+		return fmt.Errorf("ошибка переименования временного файла: %w", err)
+	}
+	d.Logger.Info("Файл скачан: %s", req.Path)
+	return nil
+}
+
+// partFile возвращает путь недокачанной части. В имя входит хэш URL, поэтому докачивается
+// только файл с того же адреса, а не чужие байты, оставшиеся в том же пути.
+func partFile(req Request) string {
+	sum := sha256.Sum256([]byte(req.URL))
+	return req.Path + "." + hex.EncodeToString(sum[:6]) + ".part"
+}
+
+// fetch выполняет одну попытку скачивания, дописывая partPath с текущего размера.
+func (d *Downloader) fetch(req Request, partPath string) error {
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	httpReq, err := http.NewRequest("GET", req.URL, nil)
+	if err != nil {
+		return &permanentError{fmt.Errorf("ошибка создания запроса для скачивания %s: %w", req.URL, err)}
+	}
+	if offset > 0 {
+		httpReq.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := d.Client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("ошибка при HTTP GET запросе для скачивания: %w", err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if start := rangeStart(resp.Header.Get("Content-Range")); start != offset {
+			os.Remove(partPath)
+			return fmt.Errorf("сервер вернул диапазон с позиции %d вместо %d, скачивание начнется заново", start, offset)
+		}
+		flags |= os.O_APPEND
+		d.Logger.Info("Продолжение скачивания %s с %d байт", req.URL, offset)
+	case resp.StatusCode == http.StatusOK:
+		// Сервер не поддерживает Range или файла еще не было — пишем с начала
+		offset = 0
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		os.Remove(partPath)
+		return fmt.Errorf("сервер отклонил докачку с %d байт, скачивание начнется заново", offset)
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("получен некорректный статус при скачивании: %d", resp.StatusCode)
+	default:
+		return &permanentError{fmt.Errorf("получен некорректный статус при скачивании: %d", resp.StatusCode)}
+	}
+
+	if err := checkContentType(resp.Header.Get("Content-Type"), req.ContentTypes); err != nil {
+		return &permanentError{err}
+	}
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	maxSize := d.Config.MaxSizeMB << 20
+	if maxSize > 0 && total > maxSize {
+		return &permanentError{fmt.Errorf("файл %s больше допустимого размера: %d байт при лимите %d МБ", req.URL, total, d.Config.MaxSizeMB)}
+	}
+
+	out, err := os.OpenFile(partPath, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла для скачивания: %w", err)
+	}
+	defer out.Close()
+
+	counter := &progressWriter{
+		downloaded: offset,
+		total:      total,
+		maxSize:    maxSize,
+		interval:   d.Config.ProgressInterval,
+		lastReport: time.Now(),
+		report: func(done, total int64) {
+			if total > 0 {
+				d.Logger.Info("Скачано %.1f%% (%d из %d байт): %s", float64(done)*100/float64(total), done, total, filepath.Base(req.Path))
+			} else {
+				d.Logger.Info("Скачано %d байт: %s", done, filepath.Base(req.Path))
+			}
+		},
+	}
+	if _, err := io.Copy(out, io.TeeReader(resp.Body, counter)); err != nil {
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return err
+		}
+		return fmt.Errorf("ошибка записи скачанного файла: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("ошибка записи скачанного файла: %w", err)
+	}
+	if total > 0 && counter.downloaded != total {
+		return fmt.Errorf("скачано %d байт из %d", counter.downloaded, total)
+	}
+	return nil
+}
+
+// progressWriter считает скачанные байты, ограничивает размер и периодически сообщает о прогрессе.
+type progressWriter struct {
+	downloaded int64
+	total      int64
+	maxSize    int64
+	interval   time.Duration
+	lastReport time.Time
+	report     func(done, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.downloaded += int64(len(p))
+	if w.maxSize > 0 && w.downloaded > w.maxSize {
+		return 0, &permanentError{fmt.Errorf("скачивание превысило лимит %d байт", w.maxSize)}
+	}
+	if time.Since(w.lastReport) >= w.interval {
+		w.lastReport = time.Now()
+		w.report(w.downloaded, w.total)
+	}
+	return len(p), nil
+}
+
+// rangeStart извлекает начальную позицию из заголовка "Content-Range: bytes 100-199/200".
+func rangeStart(header string) int64 {
+	spec := strings.TrimPrefix(header, "bytes ")
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return -1
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(start), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return value
+}
+
+// checkContentType проверяет Content-Type ответа по списку допустимых префиксов.
+func checkContentType(header string, allowed []string) error {
+	if len(allowed) == 0 || header == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		mediaType = header
+	}
+	mediaType = strings.ToLower(mediaType)
+	if mediaType == "application/octet-stream" || mediaType == "binary/octet-stream" {
+		return nil
+	}
+	for _, prefix := range allowed {
+		if strings.HasPrefix(mediaType, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("неожиданный тип содержимого %q, ожидается %s", mediaType, strings.Join(allowed, ", "))
+}
+
+// verifyChecksum сравнивает контрольную сумму файла с ожидаемой.
+func verifyChecksum(path, expected string) error {
+	algorithm, sum, ok := strings.Cut(expected, ":")
+	if !ok {
+		algorithm, sum = "sha256", expected
+	}
+
+	var h hash.Hash
+	switch strings.ToLower(algorithm) {
+	case "sha256":
+		h = sha256.New()
+	case "md5":
+		h = md5.New()
+	default:
+		return fmt.Errorf("неподдерживаемый алгоритм контрольной суммы: %s", algorithm)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть файл для проверки контрольной суммы: %w", err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(h, file); err != nil {
+		return fmt.Errorf("ошибка чтения файла для проверки контрольной суммы: %w", err)
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actual, strings.TrimSpace(sum)) {
+		return fmt.Errorf("контрольная сумма не совпадает: ожидается %s, получено %s:%s", expected, algorithm, actual)
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+// internal/jobs/queue.go
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/pipeline"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/internal/uploader"
+	"ai-content-gen/internal/workspace"
+	"ai-content-gen/pkg/utils"
+)
+
+const (
+	defaultMaxQueued   = 100
+	defaultMaxAttempts = 3
+)
+
+// Ошибки, по которым API выбирает HTTP-статус ответа.
+var (
+	ErrInvalidJob  = errors.New("некорректное задание")
+	ErrJobNotFound = errors.New("задание не найдено")
+	ErrQueueFull   = errors.New("очередь заданий переполнена")
+	ErrJobState    = errors.New("действие недоступно в текущем статусе задания")
+)
+
+// Request — параметры нового задания.
+type Request struct {
+	Channel   string    `json:"channel,omitempty"`
+	Topic     string    `json:"topic,omitempty"`
+	Style     string    `json:"style,omitempty"`
+	Platforms []string  `json:"platforms,omitempty"`
+	PublishAt time.Time `json:"publish_at,omitzero"`
+	// Review — остановить готовый ролик до решения проверяющего; не задано — по review.enabled
+	Review *bool `json:"review,omitempty"`
+}
+
+// Queue принимает задания и управляет ими в очереди базы истории. Выполняют задания обработчики Worker,
+// в том числе в других процессах и на других машинах с общим диском.
+type Queue struct {
+	Config    *config.Config
+	Store     *store.Store
+	MaxQueued int // Сколько заданий может ждать в очереди
+	Logger    *utils.Logger
+}
+
+// NewQueue создает очередь заданий поверх базы истории.
+func NewQueue(cfg *config.Config, history *store.Store, logger *utils.Logger) *Queue {
+	maxQueued := cfg.App.Jobs.MaxQueued
+	if maxQueued <= 0 {
+		maxQueued = defaultMaxQueued
+	}
+	return &Queue{Config: cfg, Store: history, MaxQueued: maxQueued, Logger: logger}
+}
+
+// Submit проверяет параметры и ставит новое задание в очередь.
+func (q *Queue) Submit(req Request) (*store.Job, error) {
+	if err := q.validate(&req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJob, err)
+	}
+	queued, err := q.Store.CountJobs(store.JobQueued)
+	if err != nil {
+		return nil, err
+	}
+	if queued >= q.MaxQueued {
+		return nil, ErrQueueFull
+	}
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	review := q.Config.App.Review.Enabled
+	if req.Review != nil {
+		review = *req.Review
+	}
+	err = q.Store.EnqueueJob(store.Job{
+		ID:          id,
+		Channel:     req.Channel,
+		Topic:       strings.TrimSpace(req.Topic),
+		Style:       strings.TrimSpace(req.Style),
+		Platforms:   req.Platforms,
+		PublishAt:   req.PublishAt,
+		Review:      review,
+		MaxAttempts: q.maxAttempts(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	q.Logger.Info("Задание %s поставлено в очередь (канал %q, тема %q)", id, req.Channel, req.Topic)
+	return q.Get(id)
+}
+
+// AwaitReview ставит на проверку ролик запуска, начатого вне очереди (по расписанию демона или командой run),
+// чтобы его можно было одобрить или отклонить так же, как ролики заданий. Одобренный ролик публикует обработчик очереди.
+func (q *Queue) AwaitReview(req pipeline.Request, result *pipeline.Result) (*store.Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	err = q.Store.AddReviewJob(store.Job{
+		ID:          id,
+		Channel:     result.Channel,
+		Topic:       result.Topic,
+		Style:       strings.TrimSpace(req.Style),
+		Platforms:   req.Platforms,
+		PublishAt:   req.PublishAt,
+		RunID:       result.RunID,
+		Dir:         result.Dir,
+		Idea:        result.Idea,
+		VideoPath:   result.VideoPath,
+		MaxAttempts: q.maxAttempts(),
+	}, pipeline.StagePublish)
+	if err != nil {
+		return nil, err
+	}
+	q.Logger.Info("Ролик запуска %s ждет проверки как задание %s (jobs approve %s)", result.RunID, id, id)
+	return q.Get(id)
+}
+
+// maxAttempts возвращает число попыток нового задания.
+func (q *Queue) maxAttempts() int {
+	if q.Config.App.Jobs.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return q.Config.App.Jobs.MaxAttempts
+}
+
+// validate проверяет канал и платформы задания.
+func (q *Queue) validate(req *Request) error {
+	channel, err := q.Config.App.Channel(req.Channel)
+	if err != nil {
+		return err
+	}
+	req.Channel = channel.Name
+	for i, platform := range req.Platforms {
+		platform = strings.ToLower(strings.TrimSpace(platform))
+		if !slices.Contains(uploader.Platforms, uploader.PlatformType(platform)) {
+			return fmt.Errorf("неизвестная платформа %q", platform)
+		}
+		req.Platforms[i] = platform
+	}
+	return nil
+}
+
+// Get возвращает задание или ErrJobNotFound.
+func (q *Queue) Get(id string) (*store.Job, error) {
+	job, err := q.Store.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// List возвращает задания со статусом status (пусто — все), новые первыми.
+func (q *Queue) List(status string, limit int) ([]store.Job, error) {
+	return q.Store.ListJobs(status, limit)
+}
+
+// Cancel отменяет задание. Ждущее очереди или проверки задание снимается сразу, выполняющееся обработчик прерывает
+// перед следующим этапом или сценой, узнав об отмене при продлении аренды.
+func (q *Queue) Cancel(id string) (*store.Job, error) {
+	job, err := q.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := q.Store.CancelJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return job, fmt.Errorf("%w: задание уже %s", ErrJobState, job.Status)
+	}
+	q.Logger.Info("Задание %s отменяется", id)
+	return q.Get(id)
+}
+
+// Retry возвращает в очередь отмененное, отклоненное или исчерпавшее попытки задание. Запуск продолжается с этапа stage,
+// а если он пуст — с упавшего этапа; результаты предыдущих этапов берутся из манифеста запуска.
+func (q *Queue) Retry(id, stage string) (*store.Job, error) {
+	if stage != "" && !slices.Contains(pipeline.Stages, stage) {
+		return nil, fmt.Errorf("%w: неизвестный этап %q, допустимые: %v", ErrInvalidJob, stage, pipeline.Stages)
+	}
+	job, err := q.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == store.JobRejected {
+		// Повторенный ролик проверяется заново, даже если пересобирать его не нужно; решение сбрасывается
+		// до постановки в очередь, чтобы обработчик не увидел прежний отказ
+		if err := q.resetReview(job); err != nil {
+			return job, err
+		}
+	}
+	ok, err := q.Store.RetryJob(id, stage)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return job, fmt.Errorf("%w: повторить можно только отмененное, отклоненное или исчерпавшее попытки задание, а оно %s", ErrJobState, job.Status)
+	}
+	q.Logger.Info("Задание %s поставлено на повтор", id)
+	return q.Get(id)
+}
+
+// Approve одобряет ролик задания, ждущего проверки, и возвращает задание в очередь для публикации.
+// Ролик выходит по расписанию задания или платформ.
+func (q *Queue) Approve(id, reviewer, comment string) (*store.Job, error) {
+	return q.approve(id, reviewer, comment, false)
+}
+
+// Publish одобряет ролик задания, ждущего проверки, и публикует его сразу, без отложенной публикации.
+func (q *Queue) Publish(id, reviewer, comment string) (*store.Job, error) {
+	return q.approve(id, reviewer, comment, true)
+}
+
+func (q *Queue) approve(id, reviewer, comment string, publishNow bool) (*store.Job, error) {
+	job, ws, err := q.awaitingReview(id)
+	if err != nil {
+		return job, err
+	}
+	// Решение записывается в манифест до смены статуса: обработчик проверяет его перед публикацией
+	ws.Manifest.SetReview(workspace.ReviewApproved, reviewer, comment)
+	if _, err := ws.WriteManifest(); err != nil {
+		return job, err
+	}
+	ok, err := q.Store.ApproveJob(id, publishNow)
+	if err != nil {
+		return job, err
+	}
+	if !ok {
+		return job, fmt.Errorf("%w: задание больше не ждет проверки", ErrJobState)
+	}
+	q.Logger.Info("Задание %s одобрено проверяющим %s", id, reviewer)
+	return q.Get(id)
+}
+
+// Reject отклоняет ролик задания, ждущего проверки. Ролик не публикуется; задание можно повторить
+// с этапа, результат которого не устроил проверяющего.
+func (q *Queue) Reject(id, reviewer, comment string) (*store.Job, error) {
+	job, ws, err := q.awaitingReview(id)
+	if err != nil {
+		return job, err
+	}
+	ok, err := q.Store.RejectJob(id, comment)
+	if err != nil {
+		return job, err
+	}
+	if !ok {
+		return job, fmt.Errorf("%w: задание больше не ждет проверки", ErrJobState)
+	}
+	ws.Manifest.SetReview(workspace.ReviewRejected, reviewer, comment)
+	rejectErr := fmt.Errorf("ролик отклонен при проверке: %s", comment)
+	ws.Manifest.Finish(rejectErr)
+	if _, err := ws.WriteManifest(); err != nil {
+		q.Logger.Warn("Не удалось сохранить решение в манифест запуска: %v", err)
+	}
+	q.Store.FinishRun(job.RunID, rejectErr)
+	q.Logger.Info("Задание %s отклонено проверяющим %s: %s", id, reviewer, comment)
+	return q.Get(id)
+}
+
+// Regenerate возвращает в очередь задание, у которого нужно заново сгенерировать сцены scenes (номера с 1):
+// остальные сегменты берутся из прошлой попытки, ролик пересобирается и, если задание проходит проверку,
+// снова ждет ее. Сегменты хранятся только у незавершенного запуска, поэтому выполненное задание перегенерировать нельзя.
+func (q *Queue) Regenerate(id string, scenes []int) (*store.Job, error) {
+	if len(scenes) == 0 {
+		return nil, fmt.Errorf("%w: не указаны сцены", ErrInvalidJob)
+	}
+	job, err := q.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := q.Manifest(job)
+	if err != nil {
+		return job, err
+	}
+	if manifest == nil || len(manifest.Prompts) == 0 {
+		return job, fmt.Errorf("%w: у задания еще нет сцен", ErrJobState)
+	}
+	for _, scene := range scenes {
+		if scene < 1 || scene > len(manifest.Prompts) {
+			return job, fmt.Errorf("%w: в сценарии нет сцены %d, всего сцен %d", ErrInvalidJob, scene, len(manifest.Prompts))
+		}
+	}
+	ok, err := q.Store.RegenerateJob(id, pipeline.StageSegments, scenes)
+	if err != nil {
+		return job, err
+	}
+	if !ok {
+		return job, fmt.Errorf("%w: перегенерировать сцены можно у задания, которое ждет проверки, отклонено, отменено или исчерпало попытки, а оно %s", ErrJobState, job.Status)
+	}
+	q.Logger.Info("Задание %s поставлено на перегенерацию сцен %v", id, scenes)
+	return q.Get(id)
+}
+
+// EditMetadata изменяет заголовок, описание и теги ролика для платформ до одобрения.
+// Пустые поля правки оставляют прежние значения.
+func (q *Queue) EditMetadata(id string, edits []workspace.PublishMetadata) (*store.Job, error) {
+	job, ws, err := q.awaitingReview(id)
+	if err != nil {
+		return job, err
+	}
+	for _, edit := range edits {
+		platform := strings.ToLower(strings.TrimSpace(edit.Platform))
+		metadata, ok := ws.Manifest.PublishMetadata(platform)
+		if !ok {
+			return job, fmt.Errorf("%w: ролик не публикуется на платформе %q", ErrInvalidJob, edit.Platform)
+		}
+		if edit.Title != "" {
+			metadata.Title = edit.Title
+		}
+		if edit.Description != "" {
+			metadata.Description = edit.Description
+		}
+		if edit.Tags != "" {
+			metadata.Tags = edit.Tags
+		}
+		ws.Manifest.SetPublishMetadata(metadata)
+	}
+	if _, err := ws.WriteManifest(); err != nil {
+		return job, err
+	}
+	q.Logger.Info("Метаданные задания %s изменены", id)
+	return job, nil
+}
+
+// Manifest возвращает манифест запуска задания или nil, если запуск еще не начался.
+func (q *Queue) Manifest(job *store.Job) (*workspace.Manifest, error) {
+	if job.Dir == "" {
+		return nil, nil
+	}
+	return workspace.ReadManifest(job.Dir)
+}
+
+// awaitingReview возвращает задание, ждущее проверки, и открытую рабочую директорию его запуска.
+func (q *Queue) awaitingReview(id string) (*store.Job, *workspace.Workspace, error) {
+	job, err := q.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != store.JobAwaitingReview {
+		return job, nil, fmt.Errorf("%w: задание не ждет проверки, оно %s", ErrJobState, job.Status)
+	}
+	ws, err := workspace.Open(q.Config.App.Workspace, job.RunID, q.Logger)
+	if err != nil {
+		return job, nil, err
+	}
+	if err := ws.LoadManifest(); err != nil {
+		return job, nil, err
+	}
+	return job, ws, nil
+}
+
+// resetReview удаляет решение проверяющего из манифеста запуска задания.
+func (q *Queue) resetReview(job *store.Job) error {
+	ws, err := workspace.Open(q.Config.App.Workspace, job.RunID, q.Logger)
+	if err != nil {
+		return err
+	}
+	if err := ws.LoadManifest(); err != nil {
+		return err
+	}
+	ws.Manifest.ResetReview()
+	_, err = ws.WriteManifest()
+	return err
+}
+
+// newJobID создает сортируемый по времени идентификатор задания со случайным суффиксом.
+func newJobID() (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("не удалось создать идентификатор задания: %w", err)
+	}
+	return "job-" + time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix), nil
+}
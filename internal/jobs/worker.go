@@ -0,0 +1,208 @@
+// internal/jobs/worker.go
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/pipeline"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/pkg/utils"
+)
+
+const (
+	defaultWorkers      = 1
+	defaultLease        = 2 * time.Minute
+	defaultPollInterval = 5 * time.Second
+	defaultRetryDelay   = time.Minute
+)
+
+// Worker забирает задания из очереди и выполняет их конвейером. Пока задание выполняется,
+// обработчик продлевает его аренду; если процесс упадет, аренда истечет и задание
+// возьмет другой обработчик, продолжив запуск с упавшего этапа.
+type Worker struct {
+	Pipeline *pipeline.Pipeline
+	Store    *store.Store
+	Config   config.JobsConfig
+	ID       string // Уникальное имя обработчика: хост, pid и случайный суффикс
+	Logger   *utils.Logger
+}
+
+// NewWorker создает обработчик очереди заданий.
+func NewWorker(pipe *pipeline.Pipeline, history *store.Store, cfg config.JobsConfig, logger *utils.Logger) *Worker {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = defaultLease
+	}
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = cfg.Lease / 3
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+	return &Worker{Pipeline: pipe, Store: history, Config: cfg, ID: newWorkerID(), Logger: logger}
+}
+
+// Run выполняет задания в Config.Workers параллельных потоках до отмены ctx.
+// После отмены новые задания не берутся, а выполняющиеся дорабатывают.
+func (w *Worker) Run(ctx context.Context) {
+	w.Logger.Info("Обработчик %s: потоков %d, аренда %s", w.ID, w.Config.Workers, w.Config.Lease)
+	var wg sync.WaitGroup
+	for range w.Config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// loop берет задания по одному, пока ctx не отменен.
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := w.claim()
+		if err != nil {
+			w.Logger.Warn("Обработчик %s: %v", w.ID, err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.Config.PollInterval):
+			}
+			continue
+		}
+		w.execute(context.WithoutCancel(ctx), job)
+	}
+}
+
+// claim возвращает в очередь задания упавших обработчиков и берет следующее доступное задание.
+func (w *Worker) claim() (*store.Job, error) {
+	expired, err := w.Store.ExpireJobLeases()
+	if err != nil {
+		return nil, err
+	}
+	if expired > 0 {
+		w.Logger.Warn("Аренда истекла у заданий: %d, они возвращены в очередь", expired)
+	}
+	return w.Store.ClaimJob(w.ID, w.Config.Lease)
+}
+
+// execute выполняет задание, продлевая его аренду, и сохраняет итог.
+func (w *Worker) execute(ctx context.Context, job *store.Job) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var canceled atomic.Bool
+	stop := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		w.heartbeat(job.ID, cancel, &canceled, stop)
+	}()
+
+	req := pipeline.Request{
+		Channel:   job.Channel,
+		Topic:     job.Topic,
+		Style:     job.Style,
+		Platforms: job.Platforms,
+		PublishAt: job.PublishAt,
+		Review:    job.Review,
+		Scenes:    job.RetryScenes,
+		OnProgress: func(progress pipeline.Progress) {
+			if err := w.Store.UpdateJobProgress(job.ID, w.ID, progress.RunID, progress.Dir, progress.Stage, progress.Step, progress.Steps); err != nil {
+				w.Logger.Warn("Задание %s: %v", job.ID, err)
+			}
+		},
+	}
+	var result *pipeline.Result
+	var runErr error
+	if job.RunID != "" {
+		w.Logger.Info("Задание %s (попытка %d из %d): продолжение запуска %s", job.ID, job.Attempts, job.MaxAttempts, job.RunID)
+		result, runErr = w.Pipeline.Resume(ctx, job.RunID, job.RetryStage, req)
+	} else {
+		w.Logger.Info("Задание %s (попытка %d из %d): новый запуск", job.ID, job.Attempts, job.MaxAttempts)
+		result, runErr = w.Pipeline.Run(ctx, req)
+	}
+	close(stop)
+	<-heartbeatDone
+
+	var jobResult store.JobResult
+	if result != nil {
+		jobResult = store.JobResult{RunID: result.RunID, Dir: result.Dir, Topic: result.Topic, Idea: result.Idea, VideoPath: result.VideoPath}
+	}
+	if errors.Is(runErr, pipeline.ErrAwaitingReview) {
+		jobResult.AwaitingReview, runErr = true, nil
+	}
+	// Пауза перед повтором растет с каждой попыткой, чтобы не упираться в ту же временную ошибку
+	retryDelay := w.Config.RetryDelay * time.Duration(job.Attempts)
+	status, err := w.Store.FinishJob(job.ID, w.ID, jobResult, runErr, canceled.Load(), retryDelay)
+	switch {
+	case errors.Is(err, store.ErrLeaseLost):
+		w.Logger.Warn("Задание %s: аренда потеряна, итог попытки не сохранен", job.ID)
+	case err != nil:
+		w.Logger.Error("Задание %s: %v", job.ID, err)
+	case status == store.JobCompleted:
+		w.Logger.Info("Задание %s выполнено: %s", job.ID, jobResult.VideoPath)
+	case status == store.JobAwaitingReview:
+		w.Logger.Info("Задание %s ждет проверки: %s", job.ID, jobResult.VideoPath)
+	case status == store.JobCanceled:
+		w.Logger.Warn("Задание %s отменено", job.ID)
+	case status == store.JobQueued:
+		w.Logger.Warn("Задание %s завершилось ошибкой и будет повторено через %s: %v", job.ID, retryDelay, runErr)
+	default:
+		w.Logger.Error("Задание %s исчерпало попытки (%d) и перенесено в dead: %v", job.ID, job.Attempts, runErr)
+	}
+}
+
+// heartbeat продлевает аренду задания до закрытия stop. Запрос на отмену или потерянная аренда
+// прерывают запуск через cancel; аренда продлевается и после отмены, пока запуск не остановится.
+func (w *Worker) heartbeat(id string, cancel context.CancelFunc, canceled *atomic.Bool, stop <-chan struct{}) {
+	ticker := time.NewTicker(w.Config.Heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		cancelRequested, err := w.Store.HeartbeatJob(id, w.ID, w.Config.Lease)
+		switch {
+		case errors.Is(err, store.ErrLeaseLost):
+			// Задание уже могли отдать другому обработчику: второй запуск того же задания недопустим
+			w.Logger.Error("Задание %s: аренда потеряна, запуск прерывается", id)
+			cancel()
+			return
+		case err != nil:
+			w.Logger.Warn("Задание %s: %v", id, err)
+		case cancelRequested && !canceled.Load():
+			w.Logger.Warn("Задание %s: запрошена отмена, запуск прервется перед следующим этапом или сценой", id)
+			canceled.Store(true)
+			cancel()
+		}
+	}
+}
+
+// newWorkerID создает имя обработчика, по которому видно, на какой машине и в каком процессе выполняется задание.
+func newWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "worker"
+	}
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,188 @@
+// internal/pipeline/helpers.go
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/internal/uploader"
+	"ai-content-gen/internal/video"
+	"ai-content-gen/internal/workspace"
+	"ai-content-gen/pkg/utils"
+)
+
+// buildOverlays собирает надписи ролика: хук в начале, подписи к сценам и призыв к действию в конце.
+// timeScale: коэффициент сжатия времени сцен после ускорения в FitDuration (1 — без ускорения).
+func buildOverlays(script *ai.Script, scenes []ai.Scene, sceneStarts []float64, concatDuration, finalDuration, timeScale float64, cfg config.OverlayConfig) []video.TextOverlay {
+	var overlays []video.TextOverlay
+	if script.Hook != "" {
+		overlays = append(overlays, video.TextOverlay{
+			Text:      script.Hook,
+			Start:     0,
+			End:       min(cfg.Hook.Duration, finalDuration),
+			Position:  cfg.Hook.Position,
+			Animation: cfg.Hook.Animation,
+			FontSize:  cfg.Hook.FontSize,
+		})
+	}
+
+	for i, scene := range scenes {
+		if scene.Overlay == "" {
+			continue
+		}
+		end := concatDuration
+		if i+1 < len(sceneStarts) {
+			end = sceneStarts[i+1]
+		}
+		overlays = append(overlays, video.TextOverlay{
+			Text:      scene.Overlay,
+			Start:     sceneStarts[i] * timeScale,
+			End:       min(end*timeScale, finalDuration),
+			Position:  cfg.Scene.Position,
+			Animation: cfg.Scene.Animation,
+			FontSize:  cfg.Scene.FontSize,
+		})
+	}
+
+	if script.CallToAction != "" {
+		overlays = append(overlays, video.TextOverlay{
+			Text:      script.CallToAction,
+			Start:     max(0, finalDuration-cfg.CTA.Duration),
+			End:       finalDuration,
+			Position:  cfg.CTA.Position,
+			Animation: cfg.CTA.Animation,
+			FontSize:  cfg.CTA.FontSize,
+		})
+	}
+	return overlays
+}
+
+// brandingOverhead возвращает суммарную длительность заставок и финальной карточки канала.
+func brandingOverhead(editor *video.VideoEditor, branding config.BrandingConfig, logger *utils.Logger) float64 {
+	var total float64
+	for _, clip := range []string{branding.Intro, branding.Outro} {
+		if clip == "" {
+			continue
+		}
+		duration, err := editor.ProbeDuration(clip)
+		if err != nil {
+			logger.Warn("Не удалось определить длительность заставки %s: %v", clip, err)
+			continue
+		}
+		total += duration
+	}
+	if branding.EndCard.Image != "" || branding.EndCard.Text != "" {
+		total += branding.EndCard.Duration
+	}
+	return total
+}
+
+// applyBranding накладывает логотип канала и добавляет заставки и финальную карточку,
+// приводя их к разрешению и частоте кадров ролика. Возвращает путь к итоговому видео.
+func applyBranding(editor *video.VideoEditor, inputPath, workDir, format string, width, height, fps int, branding config.BrandingConfig, overlayCfg config.OverlayConfig) (string, error) {
+	logo := branding.Logo
+	currentPath, err := editor.ApplyWatermark(inputPath, filepath.Join(workDir, "watermarked."+format), width, video.LogoOptions{
+		Path:     logo.Path,
+		Position: logo.Position,
+		Opacity:  logo.Opacity,
+		Scale:    logo.Scale,
+		Margin:   logo.Margin,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{}
+	if branding.Intro != "" {
+		parts = append(parts, branding.Intro)
+	}
+	parts = append(parts, currentPath)
+	if branding.Outro != "" {
+		parts = append(parts, branding.Outro)
+	}
+	if endCard := branding.EndCard; (endCard.Image != "" || endCard.Text != "") && endCard.Duration > 0 {
+		cardPath, err := editor.RenderEndCard(endCard.Image, endCard.Text, filepath.Join(workDir, "end_card."+format), video.CoverStyle{
+			Width:     width,
+			Height:    height,
+			FontFile:  overlayCfg.FontFile,
+			FontSize:  overlayCfg.CTA.FontSize,
+			FontColor: overlayCfg.FontColor,
+		}, endCard.Duration, fps)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, cardPath)
+	}
+
+	if len(parts) == 1 {
+		return currentPath, nil
+	}
+	return editor.ConcatenateNormalized(parts, filepath.Join(workDir, "branded."+format), width, height, fps)
+}
+
+// renditionSpecs преобразует варианты кодирования из конфигурации в параметры для VideoEditor.
+func renditionSpecs(renditions []config.RenditionConfig) ([]video.RenditionSpec, error) {
+	specs := make([]video.RenditionSpec, 0, len(renditions))
+	for _, r := range renditions {
+		width, height, err := video.ParseResolution(r.Resolution)
+		if err != nil {
+			return nil, fmt.Errorf("вариант '%s': %w", r.Name, err)
+		}
+		specs = append(specs, video.RenditionSpec{
+			Name:         r.Name,
+			Width:        width,
+			Height:       height,
+			Fit:          r.Fit,
+			VideoCodec:   r.VideoCodec,
+			Profile:      r.Profile,
+			Preset:       r.Preset,
+			VideoBitrate: r.VideoBitrate,
+			CRF:          r.CRF,
+			AudioBitrate: r.AudioBitrate,
+		})
+	}
+	return specs, nil
+}
+
+// writeSegmentParams сохраняет параметры запросов к видеомодели для всех сегментов в JSON-файл.
+func writeSegmentParams(path string, segments []*ai.VideoSegment) error {
+	data, err := json.MarshalIndent(segments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка маршалинга параметров сегментов: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// publicationRecord переводит результат загрузки в запись истории.
+func publicationRecord(runID string, platform uploader.PlatformType, path, url string, err error) store.Publication {
+	publication := store.Publication{RunID: runID, Platform: string(platform), Path: path, URL: url}
+	if err != nil {
+		publication.Error = err.Error()
+	}
+	return publication
+}
+
+// defaultMetadata возвращает заголовок, описание и теги ролика для платформы по умолчанию.
+func defaultMetadata(platform uploader.PlatformType, idea string) workspace.PublishMetadata {
+	switch platform {
+	case uploader.PlatformTikTok:
+		return workspace.PublishMetadata{
+			Platform:    string(platform),
+			Title:       fmt.Sprintf("AI Космос: %s", idea),
+			Description: "Генерация AI для TikTok! #AI #Shorts",
+			Tags:        "AI,космос,shorts",
+		}
+	default:
+		return workspace.PublishMetadata{
+			Platform:    string(platform),
+			Title:       fmt.Sprintf("AI Shorts: %s", idea),
+			Description: fmt.Sprintf("Это YouTube Shorts, сгенерированный полностью AI на тему: %s.", idea),
+			Tags:        "AI,Shorts,YouTubeShorts,AIgenerated,космическаябитва,федерация",
+		}
+	}
+}
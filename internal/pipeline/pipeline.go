@@ -0,0 +1,331 @@
+// internal/pipeline/pipeline.go
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/dedup"
+	"ai-content-gen/internal/slideshow"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/internal/thumbnail"
+	"ai-content-gen/internal/uploader"
+	"ai-content-gen/internal/video"
+	"ai-content-gen/internal/workspace"
+	"ai-content-gen/pkg/utils"
+)
+
+// DefaultTopic используется, если тема не задана, очередь канала пуста и у канала нет topics.default.
+const DefaultTopic = "космическая битва с флотом Федерации"
+
+// Этапы запуска в порядке выполнения.
+const (
+	StageScript     = "script"     // Идея и сцены
+	StagePrompts    = "prompts"    // Подробные промпты сцен
+	StageSegments   = "segments"   // Видеосегменты
+	StageAssemble   = "assemble"   // Склейка, надписи и оформление
+	StageThumbnail  = "thumbnail"  // Обложка
+	StageRenditions = "renditions" // Варианты под платформы
+	StagePublish    = "publish"    // Загрузка на платформы
+)
+
+// ErrAwaitingReview возвращается, когда готовый ролик остановлен перед публикацией до решения проверяющего.
+// Запуск продолжается повтором с этапа publish после одобрения.
+var ErrAwaitingReview = errors.New("ролик ждет проверки перед публикацией")
+
+// Stages перечисляет этапы в порядке выполнения.
+var Stages = []string{StageScript, StagePrompts, StageSegments, StageAssemble, StageThumbnail, StageRenditions, StagePublish}
+
+// Progress — ход запуска: текущий этап и, для этапов по сценам, номер сцены.
+type Progress struct {
+	RunID string
+	Dir   string // Рабочая директория запуска
+	Stage string
+	// Step и Steps — номер обрабатываемой сцены и число сцен этапа, а на этапах монтажа —
+	// обработанные и ожидаемые секунды результата текущей команды FFmpeg. 0 — этап только начался
+	// или не разбит на шаги.
+	Step  int
+	Steps int
+}
+
+// ProgressFunc получает уведомления о ходе запуска.
+type ProgressFunc func(Progress)
+
+// Pipeline выполняет полный цикл создания ролика: сценарий, сегменты, монтаж и публикацию.
+// Сервисы создаются заново для каждого запуска, поэтому запуски не делят обработчики манифеста.
+type Pipeline struct {
+	Config *config.Config
+	Store  *store.Store      // nil, если база истории недоступна
+	Health *ai.BackendHealth // Общее для всех запусков состояние видеобэкендов
+	Logger *utils.Logger
+}
+
+// Request — параметры одного запуска.
+type Request struct {
+	Channel string // Имя канала из config.yaml; пусто — единственный канал
+	Topic   string // Тема; пусто — из очереди тем канала
+	Style   string // Необязательный стиль ролика, добавляется в промпты
+	// Platforms — платформы для публикации; пусто — все
+	Platforms []string
+	// PublishAt — время выхода ролика на всех платформах; нулевое — по platforms.<имя>.publish_at или сразу
+	PublishAt time.Time
+	// Review — публиковать ролик только после одобрения проверяющим (см. ErrAwaitingReview)
+	Review bool
+	// Scenes — при повторе с этапа segments заново генерируются только эти сцены (номера с 1),
+	// сегменты остальных берутся из прошлой попытки
+	Scenes []int
+	// OnProgress — необязательный обработчик хода запуска
+	OnProgress ProgressFunc
+}
+
+// Result — итог запуска.
+type Result struct {
+	RunID        string
+	Dir          string
+	Channel      string
+	Topic        string
+	Idea         string
+	VideoPath    string
+	Thumbnail    string
+	Renditions   uploader.Renditions
+	Publications []store.Publication
+}
+
+// New создает новый экземпляр Pipeline.
+func New(cfg *config.Config, history *store.Store, logger *utils.Logger) *Pipeline {
+	fallback := cfg.App.AI.Video.Fallback
+	return &Pipeline{
+		Config: cfg,
+		Store:  history,
+		Health: ai.NewBackendHealth(fallback.MaxFailures, fallback.Cooldown),
+		Logger: logger,
+	}
+}
+
+// run хранит сервисы и промежуточные результаты одного запуска.
+type run struct {
+	p       *Pipeline
+	ctx     context.Context
+	cfg     *config.Config
+	channel *config.ChannelConfig
+	logger  *utils.Logger
+
+	textGen       *ai.TextGenerator
+	videoGen      *ai.VideoGenerator
+	videoEditor   *video.VideoEditor
+	thumbGen      *thumbnail.Generator
+	slideshowGen  *slideshow.Generator
+	multiUploader *uploader.MultiPlatformUploader
+	ideaChecker   *dedup.Checker
+
+	ws          *workspace.Workspace
+	req         Request
+	stage       string // Выполняемый этап
+	topic       string
+	queuedTopic *store.QueuedTopic // Тема из очереди; возвращается в очередь при ошибке
+
+	script          *ai.Script
+	durationCfg     config.DurationConfig
+	styleBible      *ai.StyleBible
+	detailedPrompts []string
+	promptScenes    []ai.Scene // Сцены, для которых удалось получить промпт
+	segments        []*ai.VideoSegment
+	segmentScenes   []ai.Scene // Сцены, для которых удалось получить видео
+	slideshowUsed   bool       // Хотя бы одна сцена собрана из изображения
+	// keptSegments — сегменты прошлой попытки, которые не генерируются заново (см. Request.Scenes)
+	keptSegments map[int]*ai.VideoSegment
+
+	result Result
+}
+
+// Run выполняет запуск от выбора темы до публикации. Отмена ctx прерывает запуск между этапами и сценами.
+// Результат возвращается и при ошибке: в нем есть идентификатор и директория запуска.
+func (p *Pipeline) Run(ctx context.Context, req Request) (*Result, error) {
+	channel, err := p.Config.App.Channel(req.Channel)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выбора канала: %w", err)
+	}
+	p.Logger.Info("Канал: %s", channel.Name)
+
+	// Каждый запуск работает в собственной директории, чтобы параллельные запуски не мешали друг другу
+	ws, err := workspace.New(p.Config.App.Workspace, p.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать рабочую директорию запуска: %w", err)
+	}
+	p.Logger.Info("Запуск %s, рабочая директория: %s", ws.RunID, ws.Dir)
+
+	r := p.newRun(ctx, channel, ws, req)
+	r.topic, r.queuedTopic = r.chooseTopic(req.Topic)
+	r.result.Topic = r.topic
+
+	models := workspace.ManifestModels{Text: p.Config.App.AI.Text.Model, Image: p.Config.App.AI.Image.Model}
+	for _, provider := range r.videoGen.Providers {
+		models.VideoBackends = append(models.VideoBackends, provider.Name())
+	}
+	r.ws.Manifest.Start(r.topic, channel.Name, models)
+	p.Store.StartRun(store.Run{ID: r.ws.RunID, Channel: channel.Name, Topic: r.topic, Workspace: r.ws.Dir})
+
+	err = r.execute(0)
+	r.finish(err)
+	return &r.result, err
+}
+
+// newRun создает сервисы запуска в рабочей директории ws.
+func (p *Pipeline) newRun(ctx context.Context, channel *config.ChannelConfig, ws *workspace.Workspace, req Request) *run {
+	cfg, logger := p.Config, p.Logger
+	r := &run{p: p, ctx: ctx, cfg: cfg, channel: channel, logger: logger, ws: ws, req: req}
+	r.result = Result{RunID: ws.RunID, Dir: ws.Dir, Channel: channel.Name}
+
+	r.textGen = ai.NewTextGenerator(cfg.TextAIEndpoint, cfg.App, logger)
+	r.textGen.Style = strings.TrimSpace(req.Style)
+	r.videoGen = ai.NewVideoGenerator(cfg.VideoAIEndpoint, cfg.VideoAIAPIKey, cfg.App, logger)
+	r.videoGen.Health = p.Health
+	for i, provider := range r.videoGen.Providers {
+		logger.Info("Видеобэкенд %d в цепочке: %s", i+1, provider.Name())
+	}
+	r.videoEditor = video.NewVideoEditor(logger)
+	imageGen := ai.NewImageGenerator(cfg.ImageAIEndpoint, cfg.ImageAIAPIKey, cfg.App, logger)
+	r.thumbGen = thumbnail.NewGenerator(cfg.App.Thumbnail, imageGen, r.videoEditor, logger)
+	r.slideshowGen = slideshow.NewGenerator(cfg.App, imageGen, r.videoEditor, logger)
+
+	r.multiUploader = p.newUploader()
+
+	// Манифест запуска: все промпты и ответы, параметры сегментов, команды FFmpeg и результаты публикации
+	r.textGen.OnCall = ws.Manifest.RecordTextCall
+	r.videoEditor.OnCommand = ws.Manifest.RecordCommand
+	r.videoEditor.OnProgress = r.ffmpegProgress
+
+	// Проверка идей на повторы по эмбеддингам прошлых идей канала
+	if embeddingsCfg := cfg.App.AI.Embeddings; embeddingsCfg.Enabled && p.Store != nil {
+		embeddingGen := ai.NewEmbeddingGenerator(cfg.EmbeddingAIEndpoint, cfg.EmbeddingAIAPIKey, cfg.App, logger)
+		r.ideaChecker = dedup.NewChecker(embeddingGen, p.Store, embeddingsCfg, logger)
+	}
+	return r
+}
+
+// newUploader создает мультиплатформенный загрузчик с ключами и вариантами ролика из конфига.
+func (p *Pipeline) newUploader() *uploader.MultiPlatformUploader {
+	multiUploader := uploader.NewMultiPlatformUploader(p.Config.YouTubeAPIKey, p.Config.TikTokAPIKey, p.Logger)
+	for platform, platformCfg := range p.Config.App.Platforms {
+		multiUploader.SetPreferredRendition(uploader.PlatformType(platform), platformCfg.Rendition)
+	}
+	return multiUploader
+}
+
+// chooseTopic выбирает тему запуска: заданную явно, из очереди тем канала,
+// из topics.default канала или DefaultTopic.
+// Второе значение — взятая из очереди тема (nil, если тема не из очереди).
+func (r *run) chooseTopic(requested string) (string, *store.QueuedTopic) {
+	if topic := strings.TrimSpace(requested); topic != "" {
+		r.logger.Info("Тема из параметров запуска: %s", topic)
+		return topic, nil
+	}
+	queued, err := r.p.Store.NextTopic(r.channel.Name, r.ws.RunID)
+	if err != nil {
+		r.logger.Warn("Не удалось взять тему из очереди: %v", err)
+	}
+	if queued != nil {
+		r.logger.Info("Тема из очереди канала (оценка %.1f, %s): %s", queued.Score, queued.Source, queued.Topic)
+		return queued.Topic, queued
+	}
+	if r.channel.Topics.Default != "" {
+		r.logger.Info("Очередь тем пуста, используется тема канала по умолчанию: %s", r.channel.Topics.Default)
+		return r.channel.Topics.Default, nil
+	}
+	return DefaultTopic, nil
+}
+
+// execute выполняет этапы по порядку, начиная с этапа с индексом from в Stages.
+// После каждого этапа манифест сохраняется, чтобы запуск можно было продолжить с упавшего этапа.
+func (r *run) execute(from int) error {
+	stages := map[string]func() error{
+		StageScript:     r.generateScript,
+		StagePrompts:    r.generatePrompts,
+		StageSegments:   r.generateSegments,
+		StageAssemble:   r.assemble,
+		StageThumbnail:  r.generateThumbnail,
+		StageRenditions: r.renderRenditions,
+		StagePublish:    r.publish,
+	}
+	for _, name := range Stages[from:] {
+		if err := r.ctx.Err(); err != nil {
+			return fmt.Errorf("запуск прерван перед этапом %s: %w", name, err)
+		}
+		r.stage = name
+		r.progress(name, 0, 0)
+		r.ws.Manifest.StartStage(name)
+		r.checkpoint()
+		err := stages[name]()
+		if errors.Is(err, ErrAwaitingReview) {
+			r.ws.Manifest.PauseStage(name)
+		} else {
+			r.ws.Manifest.FinishStage(name, err)
+		}
+		r.checkpoint()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// progress сообщает обработчику запроса о ходе запуска.
+func (r *run) progress(stage string, step, steps int) {
+	if r.req.OnProgress != nil {
+		r.req.OnProgress(Progress{RunID: r.ws.RunID, Dir: r.ws.Dir, Stage: stage, Step: step, Steps: steps})
+	}
+}
+
+// ffmpegProgress передает ход команды FFmpeg как шаги текущего этапа в секундах результата.
+// На этапах сценариев и сегментов шаги — сцены, и FFmpeg их не перебивает.
+func (r *run) ffmpegProgress(progress video.Progress) {
+	if progress.Total <= 0 || !slices.Contains([]string{StageAssemble, StageThumbnail, StageRenditions}, r.stage) {
+		return
+	}
+	steps := int(math.Ceil(progress.Total))
+	r.progress(r.stage, min(int(progress.OutTime), steps), steps)
+}
+
+// checkpoint сохраняет манифест по ходу запуска.
+func (r *run) checkpoint() {
+	if _, err := r.ws.WriteManifest(); err != nil {
+		r.logger.Warn("Не удалось сохранить манифест запуска: %v", err)
+	}
+}
+
+// finish сохраняет итог запуска в манифест и историю. Временные файлы удаляются только после успеха:
+// сегменты неудачного запуска нужны, чтобы повторить его с упавшего этапа.
+func (r *run) finish(runErr error) {
+	if errors.Is(runErr, ErrAwaitingReview) {
+		// Сегменты сохраняются: проверяющий может попросить пересобрать ролик
+		r.logger.Info("Ролик ждет проверки перед публикацией, запуск %s остановлен", r.ws.RunID)
+		r.ws.Manifest.AwaitReview()
+		r.p.Store.PauseRun(r.ws.RunID)
+		r.checkpoint()
+		return
+	}
+	if runErr == nil {
+		r.logger.Info("Очистка временных видеофайлов...")
+		r.ws.Cleanup()
+	} else {
+		r.logger.Info("Промежуточные файлы сохранены для повтора запуска: %s", r.ws.TempDir)
+	}
+	r.ws.Manifest.Finish(runErr)
+	r.p.Store.FinishRun(r.ws.RunID, runErr)
+	// Тема неудачного запуска возвращается в очередь, чтобы следующий запуск попробовал ее снова
+	if runErr != nil && r.queuedTopic != nil {
+		r.p.Store.ReleaseTopic(r.queuedTopic.ID)
+	}
+	if manifestPath, err := r.ws.WriteManifest(); err != nil {
+		r.logger.Warn("Не удалось сохранить манифест запуска: %v", err)
+	} else {
+		r.logger.Info("Манифест запуска: %s", manifestPath)
+	}
+}
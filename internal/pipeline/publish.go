@@ -0,0 +1,65 @@
+// internal/pipeline/publish.go
+package pipeline
+
+import (
+	"fmt"
+	"time"
+
+	"ai-content-gen/internal/uploader"
+	"ai-content-gen/internal/workspace"
+)
+
+// ReleaseDue загружает ролики из локальной очереди публикаций, время выхода которых наступило к now.
+// Результат записывается в очередь, историю и манифест исходного запуска. Возвращает число обработанных публикаций.
+func (p *Pipeline) ReleaseDue(now time.Time) (int, error) {
+	if p.Store == nil {
+		return 0, fmt.Errorf("очередь публикаций недоступна без базы истории")
+	}
+	due, err := p.Store.DuePublications(now)
+	if err != nil || len(due) == 0 {
+		return 0, err
+	}
+
+	multiUploader := p.newUploader()
+	released := 0
+	for _, item := range due {
+		claimed, err := p.Store.ClaimPublication(item.ID)
+		if err != nil {
+			return released, err
+		}
+		if !claimed {
+			continue
+		}
+
+		platform := uploader.PlatformType(item.Platform)
+		p.Logger.Info("Публикация %d из очереди: %s на %s (запуск %s)", item.ID, item.Path, platform, item.RunID)
+		url, uploadErr := multiUploader.Upload(platform, item.Path, item.Title, item.Description, item.Tags)
+		if uploadErr == nil && item.Thumbnail != "" {
+			if err := multiUploader.SetThumbnail(platform, url, item.Thumbnail); err != nil {
+				p.Logger.Error("Ошибка при установке обложки на %s: %v", platform, err)
+			}
+		}
+		if err := p.Store.FinishPublication(item.ID, url, uploadErr); err != nil {
+			p.Logger.Error("Не удалось сохранить итог публикации %d: %v", item.ID, err)
+		}
+		p.Store.AddPublication(publicationRecord(item.RunID, platform, item.Path, url, uploadErr))
+		p.recordReleasedUpload(item.RunID, item.Platform, item.Path, url, uploadErr)
+		released++
+	}
+	return released, nil
+}
+
+// recordReleasedUpload дописывает публикацию из очереди в манифест исходного запуска.
+func (p *Pipeline) recordReleasedUpload(runID, platform, path, url string, uploadErr error) {
+	ws, err := workspace.Open(p.Config.App.Workspace, runID, p.Logger)
+	if err == nil {
+		err = ws.LoadManifest()
+	}
+	if err == nil {
+		ws.Manifest.RecordUpload(platform, path, url, uploadErr)
+		_, err = ws.WriteManifest()
+	}
+	if err != nil {
+		p.Logger.Warn("Не удалось дописать публикацию в манифест запуска %s: %v", runID, err)
+	}
+}
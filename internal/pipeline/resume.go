@@ -0,0 +1,166 @@
+// internal/pipeline/resume.go
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/slideshow"
+	"ai-content-gen/internal/uploader"
+	"ai-content-gen/internal/workspace"
+)
+
+// Resume повторяет запуск runID начиная с этапа stage (пусто — с первого незавершенного этапа).
+// Результаты предыдущих этапов восстанавливаются из манифеста запуска; req задает стиль, платформы
+// и время публикации, как при первом запуске (канал и тема берутся из манифеста).
+func (p *Pipeline) Resume(ctx context.Context, runID, stage string, req Request) (*Result, error) {
+	ws, err := workspace.Open(p.Config.App.Workspace, runID, p.Logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.LoadManifest(); err != nil {
+		return nil, err
+	}
+	manifest := ws.Manifest
+	channel, err := p.Config.App.Channel(manifest.Channel)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выбора канала: %w", err)
+	}
+
+	// Без явного этапа запуск продолжается с первого незавершенного; если все этапы пройдены
+	// (процесс остановился уже после них), запуск только завершается
+	from := len(Stages)
+	if stage == "" {
+		stage = failedStage(manifest)
+	}
+	if stage != "" {
+		if from = slices.Index(Stages, stage); from < 0 {
+			return nil, fmt.Errorf("неизвестный этап %q, допустимые: %v", stage, Stages)
+		}
+	}
+
+	req.Channel, req.Topic = channel.Name, manifest.Topic
+	r := p.newRun(ctx, channel, ws, req)
+	r.topic = manifest.Topic
+	r.result.Topic = manifest.Topic
+	if err := r.restore(from); err != nil {
+		return nil, fmt.Errorf("запуск %s нельзя продолжить с этапа %s: %w", runID, stage, err)
+	}
+
+	if stage == "" {
+		p.Logger.Info("Все этапы запуска %s уже выполнены", runID)
+	} else {
+		p.Logger.Info("Повтор запуска %s с этапа %s", runID, stage)
+	}
+	manifest.Resume()
+	p.Store.ResumeRun(runID)
+	err = r.execute(from)
+	r.finish(err)
+	return &r.result, err
+}
+
+// failedStage возвращает первый этап, который не завершился успешно, или пустую строку.
+func failedStage(manifest *workspace.Manifest) string {
+	for _, name := range Stages {
+		if last := manifest.LastStage(name); last == nil || last.Status != workspace.StatusCompleted {
+			return name
+		}
+	}
+	return ""
+}
+
+// restore восстанавливает из манифеста результаты этапов до Stages[from] и проверяет, что их файлы на месте.
+func (r *run) restore(from int) error {
+	manifest := r.ws.Manifest
+	if from > slices.Index(Stages, StageScript) {
+		if manifest.Script == nil {
+			return fmt.Errorf("в манифесте нет сценария")
+		}
+		r.script = manifest.Script
+		r.result.Idea = r.script.Idea
+		r.durationCfg = r.durationBudget()
+	}
+	if from > slices.Index(Stages, StagePrompts) {
+		if len(manifest.Prompts) == 0 {
+			return fmt.Errorf("в манифесте нет промптов сцен")
+		}
+		for _, prompt := range manifest.Prompts {
+			r.detailedPrompts = append(r.detailedPrompts, prompt.Prompt)
+			r.promptScenes = append(r.promptScenes, prompt.Scene)
+		}
+	}
+	if from > slices.Index(Stages, StageSegments) {
+		for _, record := range manifest.Segments {
+			segment := record.VideoSegment
+			if segment.Index < 1 || segment.Index > len(r.promptScenes) {
+				return fmt.Errorf("сегмент %d не соответствует ни одной сцене", segment.Index)
+			}
+			// Сегменты нужны файлами только для склейки; после успешного запуска они уже удалены
+			if from <= slices.Index(Stages, StageAssemble) {
+				if _, err := os.Stat(segment.Path); err != nil {
+					return fmt.Errorf("файл сегмента недоступен: %w", err)
+				}
+			}
+			r.segments = append(r.segments, &segment)
+			r.segmentScenes = append(r.segmentScenes, r.promptScenes[segment.Index-1])
+			if segment.Provider == slideshow.ProviderName {
+				r.slideshowUsed = true
+			}
+		}
+		if len(r.segments) == 0 {
+			return fmt.Errorf("в манифесте нет сегментов")
+		}
+	} else {
+		if from == slices.Index(Stages, StageSegments) && len(r.req.Scenes) > 0 {
+			if err := r.keepSegments(); err != nil {
+				return err
+			}
+		}
+		manifest.ResetSegments()
+	}
+
+	// Готовые файлы ролика берутся из артефактов последних успешных этапов
+	r.result.Renditions = uploader.Renditions{}
+	for _, artifact := range manifest.Artifacts {
+		if _, err := os.Stat(artifact.Path); err != nil {
+			continue
+		}
+		switch {
+		case artifact.Kind == workspace.KindVideo && from > slices.Index(Stages, StageAssemble):
+			r.result.VideoPath = artifact.Path
+		case artifact.Kind == workspace.KindThumbnail && from > slices.Index(Stages, StageThumbnail):
+			r.result.Thumbnail = artifact.Path
+		case artifact.Kind == workspace.KindRendition && from > slices.Index(Stages, StageRenditions):
+			r.result.Renditions[artifact.Name] = artifact.Path
+		}
+	}
+	if from > slices.Index(Stages, StageAssemble) && r.result.VideoPath == "" {
+		return fmt.Errorf("файл собранного ролика недоступен")
+	}
+	return nil
+}
+
+// keepSegments запоминает сегменты прошлой попытки, кроме сцен r.req.Scenes, чтобы этап segments
+// сгенерировал заново только выбранные сцены.
+func (r *run) keepSegments() error {
+	for _, scene := range r.req.Scenes {
+		if scene < 1 || scene > len(r.promptScenes) {
+			return fmt.Errorf("в сценарии нет сцены %d", scene)
+		}
+	}
+	r.keptSegments = make(map[int]*ai.VideoSegment)
+	for _, record := range r.ws.Manifest.Segments {
+		segment := record.VideoSegment
+		if slices.Contains(r.req.Scenes, segment.Index) {
+			continue
+		}
+		if _, err := os.Stat(segment.Path); err != nil {
+			return fmt.Errorf("файл сегмента сцены %d недоступен: %w", segment.Index, err)
+		}
+		r.keptSegments[segment.Index] = &segment
+	}
+	return nil
+}
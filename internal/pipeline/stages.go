@@ -0,0 +1,546 @@
+// internal/pipeline/stages.go
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/dedup"
+	"ai-content-gen/internal/planner"
+	"ai-content-gen/internal/slideshow"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/internal/uploader"
+	"ai-content-gen/internal/video"
+	"ai-content-gen/internal/workspace"
+)
+
+// generateScript генерирует идею и сцены и распределяет между ними длительность ролика.
+func (r *run) generateScript() error {
+	generalContent, script, ideaCheck, err := r.scriptWithoutRepeats()
+	if err != nil {
+		return err
+	}
+	overallIdea := script.Idea
+	// Заголовки и описания прошлого сценария к новой идее не подходят
+	r.ws.Manifest.ResetPublishMetadata()
+	if r.ideaChecker != nil {
+		r.ideaChecker.Remember(r.ws.RunID, r.channel.Name, overallIdea, ideaCheck)
+	}
+	r.ws.Manifest.SetIdea(overallIdea)
+	r.result.Idea = overallIdea
+	r.p.Store.SaveScript(store.Script{
+		RunID:        r.ws.RunID,
+		Idea:         overallIdea,
+		Hook:         script.Hook,
+		CallToAction: script.CallToAction,
+		Content:      generalContent,
+	})
+
+	r.durationCfg = r.durationBudget()
+
+	// Распределяем длительность ролика между сценами, чтобы уложиться в лимит платформы
+	hints := make([]float64, len(script.Scenes))
+	for i, scene := range script.Scenes {
+		hints[i] = scene.Duration
+	}
+	sceneDurations := planner.NewDurationPlanner(r.durationCfg, r.logger).Plan(hints)
+	script.Scenes = script.Scenes[:len(sceneDurations)]
+
+	r.logger.Info("Общая идея: %s", overallIdea)
+	for i, scene := range script.Scenes {
+		scene.Duration = sceneDurations[i]
+		script.Scenes[i] = scene
+		r.logger.Info("Сцена %d (%.1f с): %s", i+1, scene.Duration, scene.Description)
+	}
+	r.script = script
+	r.ws.Manifest.SetScript(script)
+	return nil
+}
+
+// durationBudget возвращает ограничения длительности для сцен ролика.
+// Заставки канала входят в лимит платформы, поэтому на сцены остается меньше времени.
+func (r *run) durationBudget() config.DurationConfig {
+	durationCfg := r.cfg.App.AI.Video.Duration
+	if overhead := brandingOverhead(r.videoEditor, r.channel.Branding, r.logger); overhead > 0 {
+		r.logger.Info("Заставки канала занимают %.1f с, уменьшаем бюджет длительности сцен", overhead)
+		durationCfg.Target = max(0, durationCfg.Target-overhead)
+		durationCfg.Max = max(0, durationCfg.Max-overhead)
+	}
+	return durationCfg
+}
+
+// scriptWithoutRepeats генерирует и разбирает сценарий. Если проверка повторов включена и идея слишком похожа
+// на одну из прошлых идей канала, сценарий генерируется заново с просьбой избегать повторов,
+// не более embeddings.max_attempts раз; после этого принимается последний вариант.
+// Возвращает исходный ответ модели, разобранный сценарий и результат проверки для сохранения.
+func (r *run) scriptWithoutRepeats() (string, *ai.Script, *dedup.Result, error) {
+	checker, maxAttempts := r.ideaChecker, r.cfg.App.AI.Embeddings.MaxAttempts
+	if maxAttempts <= 0 || checker == nil {
+		maxAttempts = 1
+	}
+	var avoid []string
+	for attempt := 1; ; attempt++ {
+		content, err := r.textGen.GenerateShortsIdeaAndScenes(r.topic, avoid)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("ошибка при генерации общей идеи и сцен: %w", err)
+		}
+
+		r.logger.Info("\n--- Сгенерированная общая идея и сцены ---")
+		fmt.Println(content)
+		r.logger.Info("----------------------------------------")
+
+		script := ai.ParseScript(content, r.logger)
+		if script.Idea == "" || len(script.Scenes) == 0 {
+			return "", nil, nil, fmt.Errorf("не удалось извлечь идею или описания сцен из сгенерированного контента")
+		}
+		if checker == nil {
+			return content, script, nil, nil
+		}
+
+		result, err := checker.Check(r.channel.Name, script.Idea)
+		if err != nil {
+			r.logger.Warn("Проверка идеи на повтор пропущена: %v", err)
+			return content, script, result, nil
+		}
+		if !result.Duplicate {
+			return content, script, result, nil
+		}
+		if attempt >= maxAttempts {
+			r.logger.Warn("Идея похожа на прошлую (%.3f), но попытки исчерпаны (%d), используем ее", result.Similarity, maxAttempts)
+			return content, script, result, nil
+		}
+		r.logger.Warn("Идея повторяет прошлую (сходство %.3f с \"%s\"), генерируем заново (попытка %d из %d)", result.Similarity, result.Match, attempt+1, maxAttempts)
+		avoid = append(avoid, result.Match, script.Idea)
+	}
+}
+
+// generatePrompts генерирует подробный промпт для каждой сцены.
+func (r *run) generatePrompts() error {
+	// Стилевая библия: единые персонажи, палитра и стиль для всех сцен
+	if r.cfg.App.AI.Video.Continuity.StyleBible {
+		styleBible, err := r.textGen.GenerateStyleBible(r.script)
+		if err != nil {
+			r.logger.Warn("Не удалось сгенерировать стилевую библию, сцены будут описаны независимо: %v", err)
+		} else {
+			r.styleBible = styleBible
+		}
+	}
+
+	r.logger.Info("\n--- Генерация подробных промптов для видео ---")
+	var prompts []workspace.ScenePrompt
+	for i, scene := range r.script.Scenes {
+		r.progress(StagePrompts, i+1, len(r.script.Scenes))
+		detailedPrompt, err := r.textGen.GenerateVideoPromptForScene(r.script.Idea, scene.Description, r.styleBible)
+		if err != nil {
+			r.logger.Error("Ошибка при генерации подробного промпта для сцены %d: %v", i+1, err)
+			continue
+		}
+		r.detailedPrompts = append(r.detailedPrompts, detailedPrompt)
+		r.promptScenes = append(r.promptScenes, scene)
+		prompts = append(prompts, workspace.ScenePrompt{Scene: scene, Prompt: detailedPrompt})
+		r.logger.Info("Детальный промпт для Сцены %d:\n%s\n", i+1, detailedPrompt)
+		r.logger.Info("-------------------------------------------")
+	}
+
+	if len(r.detailedPrompts) == 0 {
+		return fmt.Errorf("не удалось сгенерировать ни одного детального промпта")
+	}
+	r.ws.Manifest.SetPrompts(prompts)
+	return nil
+}
+
+// generateSegments генерирует видеосегменты по промптам сцен.
+func (r *run) generateSegments() error {
+	r.logger.Info("\n--- Генерация всех видеосегментов ---")
+	continuity := r.cfg.App.AI.Video.Continuity
+	slideshowMode := r.cfg.App.AI.Video.Slideshow.Mode
+	for i, prompt := range r.detailedPrompts {
+		if err := r.ctx.Err(); err != nil {
+			return fmt.Errorf("запуск прерван на сцене %d: %w", i+1, err)
+		}
+		r.progress(StageSegments, i+1, len(r.detailedPrompts))
+		if segment, ok := r.keptSegments[i+1]; ok {
+			r.logger.Info("Сцена %d не перегенерируется, сегмент взят из прошлой попытки: %s", i+1, segment.Path)
+			r.addSegment(segment, i)
+			continue
+		}
+		// Явно заданная в сценарии длительность важнее плановой
+		params := r.promptScenes[i].Params
+		if params.Duration == 0 {
+			params.Duration = r.promptScenes[i].Duration
+		}
+		// Перегенерируемой сцене нужен новый seed, иначе кэш или тот же seed вернут прежний сегмент
+		if slices.Contains(r.req.Scenes, i+1) {
+			params.Seed = rand.Int63n(1<<31-1) + 1
+		}
+		// Последний кадр предыдущего сегмента служит первым кадром следующего
+		if continuity.ImageToVideo && continuity.ChainLastFrame && params.ReferenceImage == "" && len(r.segments) > 0 {
+			previous := r.segments[len(r.segments)-1].Path
+			framePath, err := r.videoEditor.ExtractLastFrame(previous, r.ws.Temp(fmt.Sprintf("last_frame_%d.png", i)))
+			if err != nil {
+				r.logger.Warn("Не удалось извлечь последний кадр из %s: %v", previous, err)
+			} else {
+				params.ReferenceImage = framePath
+			}
+		}
+		var segment *ai.VideoSegment
+		var err error
+		if slideshowMode == config.SlideshowAlways {
+			segment, err = r.slideshowGen.GenerateSegment(prompt, i+1, params.Duration, r.ws.TempDir)
+		} else {
+			segment, err = r.videoGen.GenerateVideoSegment(prompt, i+1, params, r.ws.TempDir)
+			if err != nil && slideshowMode == config.SlideshowFallback {
+				r.logger.Warn("Видеомодель не сгенерировала сцену %d, собираем ее из изображения: %v", i+1, err)
+				segment, err = r.slideshowGen.GenerateSegment(prompt, i+1, params.Duration, r.ws.TempDir)
+			}
+		}
+		if err != nil {
+			r.logger.Error("Сцена %d пропущена, ни один бэкенд не справился: %v", i+1, err)
+			continue
+		}
+		r.ws.Add(workspace.KindSegment, fmt.Sprintf("scene_%d", i+1), segment.Path)
+		r.addSegment(segment, i)
+		r.logger.Info("Видеофрагмент для Сцены %d сгенерирован и сохранен: %s", i+1, segment.Path)
+		r.logger.Info("-------------------------------------------")
+	}
+
+	if len(r.segments) == 0 {
+		return fmt.Errorf("не удалось сгенерировать ни одного видеофрагмента")
+	}
+	return nil
+}
+
+// addSegment добавляет сегмент сцены i в запуск и манифест.
+func (r *run) addSegment(segment *ai.VideoSegment, i int) {
+	if segment.Provider == slideshow.ProviderName {
+		r.slideshowUsed = true
+	}
+	r.ws.Manifest.RecordSegment(segment)
+	r.segments = append(r.segments, segment)
+	r.segmentScenes = append(r.segmentScenes, r.promptScenes[i])
+}
+
+// assemble склеивает сегменты, подгоняет длительность, накладывает надписи и оформление канала.
+func (r *run) assemble() error {
+	// Прошлое решение проверяющего относится к прежнему ролику
+	r.ws.Manifest.ResetReview()
+	cfg := r.cfg.App
+	videoFormat := cfg.AI.Video.OutputFormat
+	finalVideoPath := r.ws.Output(r.ws.FileName(r.script.Idea, "final_short", videoFormat))
+	width, height, err := video.ParseResolution(cfg.AI.Video.Resolution)
+	if err != nil {
+		return fmt.Errorf("некорректное разрешение видео: %w", err)
+	}
+
+	videoSegmentPaths := make([]string, len(r.segments))
+	for i, segment := range r.segments {
+		videoSegmentPaths[i] = segment.Path
+	}
+
+	// Фактическое начало каждой сцены в склеенном видео (для надписей)
+	sceneStarts := make([]float64, len(videoSegmentPaths))
+	var concatDuration float64
+	for i, path := range videoSegmentPaths {
+		sceneStarts[i] = concatDuration
+		segmentDuration, err := r.videoEditor.ProbeDuration(path)
+		if err != nil {
+			r.logger.Warn("Не удалось определить длительность сегмента %s, используем плановую: %v", path, err)
+			segmentDuration = r.segmentScenes[i].Duration
+		}
+		concatDuration += segmentDuration
+	}
+
+	// Клипы слайд-шоу кодируются иначе, чем ответы видеомодели, поэтому вперемешку их склеиваем с перекодированием
+	concatPath := r.ws.Temp("concatenated." + videoFormat)
+	var currentPath string
+	if r.slideshowUsed && cfg.AI.Video.Slideshow.Mode != config.SlideshowAlways {
+		currentPath, err = r.videoEditor.ConcatenateNormalized(videoSegmentPaths, concatPath, width, height, cfg.AI.Video.FPS)
+	} else {
+		currentPath, err = r.videoEditor.ConcatenateVideos(videoSegmentPaths, concatPath, cfg.AI.Video.FPS)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка при склейке видео: %w", err)
+	}
+
+	// Если видеомодель не выдержала запрошенные длительности, ускоряем или обрезаем итог
+	currentPath, err = r.videoEditor.FitDuration(currentPath, r.ws.Temp("fitted."+videoFormat), r.durationCfg.Max, r.durationCfg.MaxSpeedup)
+	if err != nil {
+		return fmt.Errorf("ошибка при подгонке длительности видео: %w", err)
+	}
+
+	// Накладываем хук, подписи к сценам и призыв к действию
+	if cfg.Overlays.Enabled {
+		finalDuration, err := r.videoEditor.ProbeDuration(currentPath)
+		if err != nil {
+			r.logger.Warn("Не удалось определить длительность видео для надписей: %v", err)
+			finalDuration = concatDuration
+		}
+		// FitDuration либо равномерно ускоряет видео (время сцен сжимается), либо обрезает его
+		timeScale := 1.0
+		if finalDuration < concatDuration && concatDuration/finalDuration <= r.durationCfg.MaxSpeedup+0.01 {
+			timeScale = finalDuration / concatDuration
+		}
+		overlays := buildOverlays(r.script, r.segmentScenes, sceneStarts, concatDuration, finalDuration, timeScale, cfg.Overlays)
+		overlayCfg := cfg.Overlays
+		currentPath, err = r.videoEditor.RenderOverlays(currentPath, r.ws.Temp("overlays."+videoFormat), width, overlays, video.OverlayStyle{
+			FontFile:    overlayCfg.FontFile,
+			FontColor:   overlayCfg.FontColor,
+			BorderColor: overlayCfg.BorderColor,
+			SafeTop:     overlayCfg.SafeZone.Top,
+			SafeBottom:  overlayCfg.SafeZone.Bottom,
+			SafeSide:    overlayCfg.SafeZone.Side,
+		})
+		if err != nil {
+			return fmt.Errorf("ошибка при наложении надписей: %w", err)
+		}
+	}
+
+	// Фирменное оформление канала: водяной знак, заставки и финальная карточка
+	currentPath, err = applyBranding(r.videoEditor, currentPath, r.ws.TempDir, videoFormat, width, height, cfg.AI.Video.FPS, r.channel.Branding, cfg.Overlays)
+	if err != nil {
+		return fmt.Errorf("ошибка при наложении оформления канала: %w", err)
+	}
+
+	if err := os.Rename(currentPath, finalVideoPath); err != nil {
+		return fmt.Errorf("не удалось переместить видео в %s: %w", finalVideoPath, err)
+	}
+	r.result.VideoPath = finalVideoPath
+	r.ws.Add(workspace.KindVideo, "final", finalVideoPath)
+	r.p.Store.AddRender(store.Render{RunID: r.ws.RunID, Name: "final", Path: finalVideoPath})
+	r.logger.Info("Финальное видео скомпилировано: %s", finalVideoPath)
+
+	// Сохраняем точные параметры генерации каждого сегмента, чтобы удачный результат можно было повторить
+	paramsPath := strings.TrimSuffix(finalVideoPath, filepath.Ext(finalVideoPath)) + "_params.json"
+	if err := writeSegmentParams(paramsPath, r.segments); err != nil {
+		r.logger.Warn("Не удалось сохранить параметры генерации: %v", err)
+	} else {
+		r.ws.Add(workspace.KindParams, "segments", paramsPath)
+		r.logger.Info("Параметры генерации сегментов сохранены: %s", paramsPath)
+	}
+	return nil
+}
+
+// generateThumbnail генерирует обложку с заголовком (используется платформами, которые это поддерживают).
+// Ошибка обложки не прерывает запуск.
+func (r *run) generateThumbnail() error {
+	if !r.cfg.App.Thumbnail.Enabled {
+		return nil
+	}
+	r.logger.Info("\n--- Генерация обложки ---")
+	coverTitle, err := r.textGen.GenerateThumbnailTitle(r.script.Idea)
+	if err != nil || coverTitle == "" {
+		r.logger.Warn("Не удалось сгенерировать заголовок обложки, используем идею: %v", err)
+		coverTitle = r.script.Idea
+	}
+	videoPath := r.result.VideoPath
+	thumbnailPath, err := r.thumbGen.Generate(videoPath, r.detailedPrompts[0], coverTitle, strings.TrimSuffix(videoPath, filepath.Ext(videoPath))+"_thumbnail.jpg")
+	if err != nil {
+		r.logger.Error("Ошибка при генерации обложки: %v", err)
+		return nil
+	}
+	r.result.Thumbnail = thumbnailPath
+	r.ws.Add(workspace.KindThumbnail, "cover", thumbnailPath)
+	return nil
+}
+
+// renderRenditions кодирует варианты ролика под разные платформы за один проход FFmpeg.
+func (r *run) renderRenditions() error {
+	r.result.Renditions = uploader.Renditions{}
+	if len(r.cfg.App.Renditions) == 0 {
+		return nil
+	}
+	r.logger.Info("\n--- Кодирование вариантов ролика ---")
+	specs, err := renditionSpecs(r.cfg.App.Renditions)
+	if err != nil {
+		return fmt.Errorf("некорректная конфигурация renditions: %w", err)
+	}
+	videoPath := r.result.VideoPath
+	baseName := strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath))
+	renditions, err := r.videoEditor.RenderRenditions(videoPath, r.ws.OutputDir, baseName, r.cfg.App.AI.Video.OutputFormat, specs)
+	if err != nil {
+		r.logger.Error("Ошибка при кодировании вариантов ролика, будет загружен основной файл: %v", err)
+		return nil
+	}
+	for name, path := range renditions {
+		r.ws.Add(workspace.KindRendition, name, path)
+		r.p.Store.AddRender(store.Render{RunID: r.ws.RunID, Name: name, Path: path})
+	}
+	r.result.Renditions = renditions
+	return nil
+}
+
+// publish отправляет финальное видео на все платформы. Ошибка одной платформы не мешает остальным.
+// Если запуск требует проверки, ролик публикуется только после одобрения проверяющим.
+func (r *run) publish() error {
+	// Метаданные сохраняются в манифест заранее, чтобы проверяющий видел их и мог изменить
+	for _, platform := range uploader.Platforms {
+		if r.selected(platform) {
+			r.metadata(platform)
+		}
+	}
+	if r.req.Review {
+		switch decision := r.ws.Manifest.ReviewDecision(); {
+		case decision == nil:
+			return ErrAwaitingReview
+		case decision.Status == workspace.ReviewRejected:
+			return fmt.Errorf("ролик отклонен при проверке: %s", decision.Comment)
+		default:
+			r.logger.Info("Публикация одобрена проверяющим %s", decision.Reviewer)
+		}
+	}
+
+	r.logger.Info("\n--- Загрузка финального видео на платформы ---")
+	// Отказ одной платформы не мешает остальным; запуск падает, только если ролик не ушел никуда
+	var published int
+	var errs []error
+	if r.publishesTo(uploader.PlatformYouTube) {
+		metadata := r.metadata(uploader.PlatformYouTube)
+		ytVideoURL, err := r.upload(uploader.PlatformYouTube, metadata.Title, metadata.Description, metadata.Tags)
+		if err != nil {
+			r.logger.Error("Ошибка при загрузке видео на YouTube: %v", err) // Не фатально, если хотим попробовать другие платформы
+			errs = append(errs, fmt.Errorf("YouTube: %w", err))
+		} else {
+			published++
+			if ytVideoURL != "" {
+				r.logger.Info("Видео успешно загружено на YouTube: %s", ytVideoURL)
+				if r.result.Thumbnail != "" {
+					if err := r.multiUploader.SetThumbnail(uploader.PlatformYouTube, ytVideoURL, r.result.Thumbnail); err != nil {
+						r.logger.Error("Ошибка при установке обложки на YouTube: %v", err)
+					}
+				}
+			}
+		}
+	}
+
+	if r.publishesTo(uploader.PlatformTikTok) {
+		metadata := r.metadata(uploader.PlatformTikTok)
+		tiktokVideoURL, err := r.upload(uploader.PlatformTikTok, metadata.Title, metadata.Description, metadata.Tags)
+		if err != nil {
+			r.logger.Error("Ошибка при загрузке видео на TikTok: %v", err)
+			errs = append(errs, fmt.Errorf("TikTok: %w", err))
+		} else {
+			published++
+			if tiktokVideoURL != "" {
+				r.logger.Info("Видео успешно загружено на TikTok: %s", tiktokVideoURL)
+			}
+		}
+	}
+
+	switch {
+	case published > 0:
+		return nil
+	case len(errs) == 0:
+		return fmt.Errorf("не выбрано ни одной платформы для публикации")
+	default:
+		return fmt.Errorf("ролик не загружен и не поставлен в очередь ни на одной платформе: %w", errors.Join(errs...))
+	}
+}
+
+// metadata возвращает метаданные ролика для платформы: измененные проверяющим или по умолчанию.
+func (r *run) metadata(platform uploader.PlatformType) workspace.PublishMetadata {
+	if metadata, ok := r.ws.Manifest.PublishMetadata(string(platform)); ok {
+		return metadata
+	}
+	metadata := defaultMetadata(platform, r.script.Idea)
+	r.ws.Manifest.SetPublishMetadata(metadata)
+	return metadata
+}
+
+// upload загружает подходящий платформе вариант ролика и записывает результат в манифест и историю.
+// Если для платформы задано время выхода, ролик загружается с отложенной публикацией
+// или, когда платформа этого не умеет, ставится в локальную очередь публикаций (тогда URL пустой).
+func (r *run) upload(platform uploader.PlatformType, title, description, tags string) (string, error) {
+	videoPath := r.multiUploader.PickRendition(platform, r.result.Renditions, r.result.VideoPath)
+	publishAt, err := r.publishTime(platform)
+	if err != nil {
+		r.logger.Warn("Время публикации на %s не определено, публикуем сразу: %v", platform, err)
+	}
+	if publishAt.IsZero() || (!r.multiUploader.SupportsScheduling(platform) && r.p.Store == nil) {
+		if !publishAt.IsZero() {
+			r.logger.Warn("База истории недоступна, отложить публикацию на %s нельзя, публикуем сразу", platform)
+		}
+		url, err := r.multiUploader.Upload(platform, videoPath, title, description, tags)
+		r.ws.Manifest.RecordUpload(string(platform), videoPath, url, err)
+		r.recordPublication(publicationRecord(r.ws.RunID, platform, videoPath, url, err))
+		return url, err
+	}
+
+	if r.multiUploader.SupportsScheduling(platform) {
+		url, err := r.multiUploader.UploadScheduled(platform, videoPath, title, description, tags, publishAt)
+		r.ws.Manifest.RecordScheduledUpload(string(platform), videoPath, url, publishAt, false, err)
+		publication := publicationRecord(r.ws.RunID, platform, videoPath, url, err)
+		publication.PublishedAt = publishAt
+		r.recordPublication(publication)
+		return url, err
+	}
+
+	id, err := r.p.Store.QueuePublication(store.ScheduledPublication{
+		RunID:       r.ws.RunID,
+		Channel:     r.channel.Name,
+		Platform:    string(platform),
+		Path:        videoPath,
+		Title:       title,
+		Description: description,
+		Tags:        tags,
+		Thumbnail:   r.result.Thumbnail,
+		PublishAt:   publishAt,
+	})
+	r.ws.Manifest.RecordScheduledUpload(string(platform), videoPath, "", publishAt, true, err)
+	if err != nil {
+		return "", err
+	}
+	r.logger.Info("Публикация на %s отложена до %s (очередь публикаций, id %d)", platform, publishAt.Format(time.DateTime), id)
+	return "", nil
+}
+
+// publishesTo сообщает, входит ли платформа в список платформ запроса, и пишет в лог пропуск платформы.
+func (r *run) publishesTo(platform uploader.PlatformType) bool {
+	if !r.selected(platform) {
+		r.logger.Info("Платформа %s не выбрана для публикации, пропускаем", platform)
+		return false
+	}
+	return true
+}
+
+// selected сообщает, входит ли платформа в список платформ запроса (пустой список — все платформы).
+func (r *run) selected(platform uploader.PlatformType) bool {
+	if len(r.req.Platforms) == 0 {
+		return true
+	}
+	for _, name := range r.req.Platforms {
+		if strings.EqualFold(name, string(platform)) {
+			return true
+		}
+	}
+	return false
+}
+
+// publishTime возвращает время выхода ролика на платформе: из запроса или ближайший час пик платформы.
+// Нулевое время или время в прошлом означает публикацию сразу.
+func (r *run) publishTime(platform uploader.PlatformType) (time.Time, error) {
+	publishAt := r.req.PublishAt
+	if publishAt.IsZero() {
+		var err error
+		if publishAt, err = r.cfg.App.Platforms[string(platform)].NextPublishTime(time.Now()); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if !publishAt.After(time.Now()) {
+		return time.Time{}, nil
+	}
+	return publishAt, nil
+}
+
+// recordPublication сохраняет публикацию в историю и итог запуска.
+func (r *run) recordPublication(publication store.Publication) {
+	r.p.Store.AddPublication(publication)
+	r.result.Publications = append(r.result.Publications, publication)
+}
@@ -0,0 +1,134 @@
+// internal/planner/duration.go
+package planner
+
+import (
+	"math"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+// DurationPlanner распределяет общую длительность ролика между сценами сценария,
+// чтобы итоговое видео укладывалось в ограничения платформы.
+type DurationPlanner struct {
+	Config config.DurationConfig
+	Logger *utils.Logger
+}
+
+// NewDurationPlanner создает новый экземпляр DurationPlanner.
+func NewDurationPlanner(cfg config.DurationConfig, logger *utils.Logger) *DurationPlanner {
+	return &DurationPlanner{
+		Config: cfg,
+		Logger: logger,
+	}
+}
+
+// Plan возвращает длительность (в секундах) для каждой сцены.
+// hints: длительности, предложенные текстовой моделью (0 — не указана); используются как веса.
+// Если все сцены не помещаются в Max даже с минимальной длительностью, лишние сцены
+// с конца отбрасываются, поэтому результат может быть короче hints.
+// Если ни Target, ни Max не заданы, возвращаются нули — длительность выбирает видеомодель.
+func (p *DurationPlanner) Plan(hints []float64) []float64 {
+	if len(hints) == 0 {
+		return nil
+	}
+
+	target := p.Config.Target
+	if target <= 0 || (p.Config.Max > 0 && target > p.Config.Max) {
+		target = p.Config.Max
+	}
+	if target <= 0 {
+		return make([]float64, len(hints))
+	}
+
+	n := len(hints)
+	minScene := p.Config.MinScene
+	if minScene > 0 && p.Config.Max > 0 {
+		switch fit := int(p.Config.Max / minScene); {
+		case fit < 1:
+			// Без сцен ролика не будет: оставляем первую, сократив ее до лимита
+			p.Logger.Warn("Даже одна сцена длительностью %.1f с не помещается в %.0f с, будет использована только первая сцена длительностью %.1f с",
+				minScene, p.Config.Max, target)
+			n, minScene = 1, target
+		case fit < n:
+			p.Logger.Warn("%d сцен не помещаются в %.0f с, будут использованы только первые %d", n, p.Config.Max, fit)
+			n = fit
+		}
+	}
+
+	// Сцены без подсказки получают средний вес среди указанных.
+	weights := make([]float64, n)
+	var hinted float64
+	var hintedCount int
+	for _, h := range hints[:n] {
+		if h > 0 {
+			hinted += h
+			hintedCount++
+		}
+	}
+	defaultWeight := 1.0
+	if hintedCount > 0 {
+		defaultWeight = hinted / float64(hintedCount)
+	}
+	for i, h := range hints[:n] {
+		if h > 0 {
+			weights[i] = h
+		} else {
+			weights[i] = defaultWeight
+		}
+	}
+
+	durations := distribute(target, weights, minScene, p.Config.MaxScene)
+
+	var total float64
+	for _, d := range durations {
+		total += d
+	}
+	p.Logger.Info("План длительности: %d сцен, всего %.1f с (цель %.1f с): %v", n, total, target, durations)
+	return durations
+}
+
+// distribute пропорционально весам делит total, удерживая каждую долю в [minVal, maxVal].
+// Сцены, упершиеся в границу, фиксируются, а остаток перераспределяется между остальными.
+func distribute(total float64, weights []float64, minVal, maxVal float64) []float64 {
+	result := make([]float64, len(weights))
+	fixed := make([]bool, len(weights))
+
+	for {
+		remaining := total
+		var freeWeight float64
+		for i, w := range weights {
+			if fixed[i] {
+				remaining -= result[i]
+			} else {
+				freeWeight += w
+			}
+		}
+		if freeWeight == 0 {
+			break
+		}
+
+		changed := false
+		for i, w := range weights {
+			if fixed[i] {
+				continue
+			}
+			d := remaining * w / freeWeight
+			switch {
+			case minVal > 0 && d < minVal:
+				d, fixed[i], changed = minVal, true, true
+			case maxVal > 0 && d > maxVal:
+				d, fixed[i], changed = maxVal, true, true
+			}
+			result[i] = d
+		}
+		if !changed {
+			break
+		}
+	}
+
+	for i := range result {
+		result[i] = math.Round(result[i]*10) / 10
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+// internal/planner/duration_test.go
+package planner
+
+import (
+	"slices"
+	"testing"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   config.DurationConfig
+		hints []float64
+		want  []float64
+	}{
+		{
+			name: "нет сцен",
+			cfg:  config.DurationConfig{Target: 30, Max: 60},
+		},
+		{
+			name:  "длительность выбирает модель",
+			hints: []float64{5, 0, 3},
+			want:  []float64{0, 0, 0},
+		},
+		{
+			name:  "без подсказок поровну",
+			cfg:   config.DurationConfig{Target: 30, Max: 60, MinScene: 3, MaxScene: 10},
+			hints: []float64{0, 0, 0},
+			want:  []float64{10, 10, 10},
+		},
+		{
+			name:  "пропорционально подсказкам",
+			cfg:   config.DurationConfig{Target: 30, Max: 60, MinScene: 3, MaxScene: 20},
+			hints: []float64{5, 10, 15},
+			want:  []float64{5, 10, 15},
+		},
+		{
+			// Сцена без подсказки получает средний вес указанных: веса 4, 6, 8
+			name:  "нормализация подсказок",
+			cfg:   config.DurationConfig{Target: 24, Max: 60},
+			hints: []float64{4, 0, 8},
+			want:  []float64{5.3, 8, 10.7},
+		},
+		{
+			name:  "подсказки масштабируются к цели",
+			cfg:   config.DurationConfig{Target: 20, Max: 60},
+			hints: []float64{20, 20},
+			want:  []float64{10, 10},
+		},
+		{
+			name:  "минимум сцены",
+			cfg:   config.DurationConfig{Target: 20, Max: 60, MinScene: 4},
+			hints: []float64{1, 1, 8},
+			want:  []float64{4, 4, 12},
+		},
+		{
+			name:  "максимум сцены",
+			cfg:   config.DurationConfig{Target: 30, Max: 60, MaxScene: 10},
+			hints: []float64{1, 1, 4},
+			want:  []float64{10, 10, 10},
+		},
+		{
+			name:  "цель больше предела",
+			cfg:   config.DurationConfig{Target: 90, Max: 30},
+			hints: []float64{0, 0},
+			want:  []float64{15, 15},
+		},
+		{
+			name:  "без цели по пределу",
+			cfg:   config.DurationConfig{Max: 20},
+			hints: []float64{0, 0},
+			want:  []float64{10, 10},
+		},
+		{
+			name:  "лишние сцены отбрасываются",
+			cfg:   config.DurationConfig{Target: 10, Max: 10, MinScene: 4},
+			hints: []float64{0, 0, 0, 0, 0},
+			want:  []float64{5, 5},
+		},
+		{
+			name:  "предел меньше минимума сцены",
+			cfg:   config.DurationConfig{Target: 45, Max: 2, MinScene: 3, MaxScene: 10},
+			hints: []float64{5, 5, 5},
+			want:  []float64{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewDurationPlanner(tt.cfg, utils.NewLogger()).Plan(tt.hints)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Plan(%v) = %v, ожидалось %v", tt.hints, got, tt.want)
+			}
+		})
+	}
+}
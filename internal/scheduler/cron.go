@@ -0,0 +1,148 @@
+// internal/scheduler/cron.go
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule — разобранное cron-выражение из пяти полей: минута, час, день месяца, месяц, день недели.
+// Поддерживаются *, списки через запятую, диапазоны a-b, шаги */n и a-b/n, а также @hourly, @daily, @weekly и @monthly.
+type Schedule struct {
+	Spec string
+
+	minute, hour, dom, month, dow uint64 // Битовые маски допустимых значений
+	domAny, dowAny                bool   // Поле начинается с * (* или */n), как в классическом cron
+}
+
+var cronMacros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseCron разбирает cron-выражение.
+func ParseCron(spec string) (*Schedule, error) {
+	expr := strings.TrimSpace(spec)
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron-выражение %q должно содержать 5 полей, получено %d", spec, len(fields))
+	}
+
+	s := &Schedule{Spec: spec}
+	bounds := []struct {
+		field    *uint64
+		min, max int
+		name     string
+	}{
+		{&s.minute, 0, 59, "минута"},
+		{&s.hour, 0, 23, "час"},
+		{&s.dom, 1, 31, "день месяца"},
+		{&s.month, 1, 12, "месяц"},
+		{&s.dow, 0, 7, "день недели"},
+	}
+	for i, b := range bounds {
+		mask, err := parseCronField(fields[i], b.min, b.max)
+		if err != nil {
+			return nil, fmt.Errorf("cron-выражение %q, поле \"%s\": %w", spec, b.name, err)
+		}
+		*b.field = mask
+	}
+	// 7 — тоже воскресенье
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny, s.dowAny = strings.HasPrefix(fields[2], "*"), strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseCronField разбирает одно поле в битовую маску.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("некорректный шаг в %q", part)
+			}
+		}
+
+		start, end := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			start, err1 = strconv.Atoi(bounds[0])
+			end, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("некорректный диапазон %q", part)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("некорректное значение %q", part)
+			}
+			start, end = value, value
+			// "5/15" означает "с 5 до конца с шагом 15"
+			if step > 1 {
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("значение %q вне диапазона %d-%d", part, min, max)
+		}
+		for v := start; v <= end; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+// Next возвращает ближайшее время срабатывания строго после after (в часовом поясе after).
+// Возвращает нулевое время, если срабатываний нет в ближайшие 5 лет (например, 31 февраля).
+func (s *Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches проверяет день месяца и день недели. Как в классическом cron, если оба поля ограничены,
+// достаточно совпадения любого из них.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,77 @@
+// internal/scheduler/cron_test.go
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Среда, 1 января 2025 года
+	after := time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{"@hourly", "@hourly", at(1, 1, 11, 0)},
+		{"@daily", "@daily", at(1, 2, 0, 0)},
+		{"@weekly", "@weekly", at(1, 5, 0, 0)},
+		{"@monthly", "@monthly", at(2, 1, 0, 0)},
+		{"строго после текущей минуты", "30 10 * * *", at(1, 2, 10, 30)},
+		{"шаг минут", "*/15 * * * *", at(1, 1, 10, 45)},
+		{"шаг часов", "0 */6 * * *", at(1, 1, 12, 0)},
+		{"диапазон с шагом и будни", "0 9-17/4 * * 1-5", at(1, 1, 13, 0)},
+		{"список", "0 8,20 * * *", at(1, 1, 20, 0)},
+		{"начало с шагом", "5/20 * * * *", at(1, 1, 10, 45)},
+		{"7 — воскресенье", "0 0 * * 7", at(1, 5, 0, 0)},
+		{"шаг дней месяца", "0 0 */2 * *", at(1, 3, 0, 0)},
+		// День месяца с * не ограничен: нужны нечетные числа, выпавшие на понедельник
+		{"шаг дней месяца и день недели", "0 0 */2 * 1", at(1, 13, 0, 0)},
+		// */7 — воскресенья; поле с * не ограничено, поэтому нужно 1 число, выпавшее на воскресенье
+		{"шаг дней недели", "0 0 1 * */7", at(6, 1, 0, 0)},
+		// Оба поля ограничены: достаточно любого совпадения
+		{"13 число или пятница", "0 0 13 * 5", at(1, 3, 0, 0)},
+		{"1 число или понедельник", "0 0 1 * 1", at(1, 6, 0, 0)},
+		{"месяц", "0 0 1 6 *", at(6, 1, 0, 0)},
+		{"31 февраля", "0 12 31 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.spec, err)
+			}
+			if got := schedule.Next(after); !got.Equal(tt.want) {
+				t.Errorf("Next(%q) = %v, ожидалось %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@yearly",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+	}
+	for _, spec := range tests {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q): ожидалась ошибка", spec)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+// internal/scheduler/daemon.go
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/jobs"
+	"ai-content-gen/internal/pipeline"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/internal/topics"
+	"ai-content-gen/pkg/utils"
+)
+
+const defaultMissedWindow = 30 * time.Minute
+
+// Daemon запускает генерацию и сбор тем по расписаниям каналов и выпускает отложенные публикации из локальной очереди.
+// Состояние слотов хранится в базе истории: слот, взятый до перезапуска, повторно не выполняется.
+type Daemon struct {
+	Config   *config.Config
+	Pipeline *pipeline.Pipeline
+	Topics   *topics.Collector
+	Store    *store.Store
+	Jobs     *jobs.Queue // Сюда попадают ролики, которые ждут проверки (review.enabled)
+	Channels []string    // Каналы демона; пусто — все каналы с расписанием
+	Logger   *utils.Logger
+}
+
+// channelSchedule — разобранное расписание одного канала.
+type channelSchedule struct {
+	channel *config.ChannelConfig
+	loc     *time.Location
+	publish []*Schedule
+	fetch   []*Schedule
+}
+
+// NewDaemon создает новый экземпляр Daemon.
+func NewDaemon(cfg *config.Config, pipe *pipeline.Pipeline, collector *topics.Collector, history *store.Store, logger *utils.Logger) *Daemon {
+	return &Daemon{
+		Config:   cfg,
+		Pipeline: pipe,
+		Topics:   collector,
+		Store:    history,
+		Jobs:     jobs.NewQueue(cfg, history, logger),
+		Logger:   logger,
+	}
+}
+
+// Run выполняет слоты расписания до отмены ctx. Запуски идут по одному, в порядке времени.
+// После отмены ctx текущий запуск доводится до конца, новые не начинаются.
+func (d *Daemon) Run(ctx context.Context) error {
+	schedules, err := d.loadSchedules()
+	if err != nil {
+		return err
+	}
+	if interrupted, err := d.Store.InterruptRunningSlots(); err != nil {
+		return err
+	} else if interrupted > 0 {
+		d.Logger.Warn("Слотов расписания, прерванных остановкой демона: %d (повторно не выполняются)", interrupted)
+	}
+	if interrupted, err := d.Store.InterruptUploadingPublications(); err != nil {
+		return err
+	} else if interrupted > 0 {
+		d.Logger.Warn("Публикаций из очереди, прерванных остановкой демона: %d (повторить: publish retry)", interrupted)
+	}
+
+	for {
+		d.releasePublications()
+
+		slot, schedule, err := d.nextSlot(schedules)
+		if err != nil {
+			return err
+		}
+		if slot == nil {
+			return fmt.Errorf("в расписаниях каналов нет предстоящих запусков")
+		}
+		wakeAt := slot.PlannedAt
+		if publishAt, err := d.Store.NextPublishAt(); err != nil {
+			d.Logger.Error("Не удалось прочитать очередь публикаций: %v", err)
+		} else if !publishAt.IsZero() && publishAt.Before(wakeAt) {
+			wakeAt = publishAt
+		}
+
+		if wait := time.Until(wakeAt); wait > 0 {
+			if wakeAt.Equal(slot.PlannedAt) {
+				d.Logger.Info("Следующий слот: канал %s, %s в %s (по расписанию %s)", slot.Channel, slot.Kind,
+					slot.PlannedAt.In(schedule.loc).Format(time.DateTime), slot.Slot.In(schedule.loc).Format("15:04"))
+			} else {
+				d.Logger.Info("Следующая публикация из очереди в %s", wakeAt.Format(time.DateTime))
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				d.Logger.Info("Демон остановлен")
+				return nil
+			case <-timer.C:
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !time.Now().Before(slot.PlannedAt) {
+			d.execute(ctx, slot, schedule)
+		}
+	}
+}
+
+// releasePublications выпускает публикации из локальной очереди, время которых наступило.
+func (d *Daemon) releasePublications() {
+	released, err := d.Pipeline.ReleaseDue(time.Now())
+	if err != nil {
+		d.Logger.Error("Ошибка при выпуске публикаций из очереди: %v", err)
+	}
+	if released > 0 {
+		d.Logger.Info("Выпущено публикаций из очереди: %d", released)
+	}
+}
+
+// loadSchedules разбирает расписания выбранных каналов.
+func (d *Daemon) loadSchedules() ([]*channelSchedule, error) {
+	names := d.Channels
+	if len(names) == 0 {
+		for _, channel := range d.Config.App.Channels {
+			if len(channel.Schedule.Cron) > 0 {
+				names = append(names, channel.Name)
+			}
+		}
+	}
+
+	var schedules []*channelSchedule
+	for _, name := range names {
+		channel, err := d.Config.App.Channel(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(channel.Schedule.Cron) == 0 {
+			return nil, fmt.Errorf("у канала %s не задано расписание (schedule.cron)", channel.Name)
+		}
+		loc, err := time.LoadLocation(channel.Schedule.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("канал %s: %w", channel.Name, err)
+		}
+		schedule := &channelSchedule{channel: channel, loc: loc}
+		for _, spec := range channel.Schedule.Cron {
+			parsed, err := ParseCron(spec)
+			if err != nil {
+				return nil, fmt.Errorf("канал %s: %w", channel.Name, err)
+			}
+			schedule.publish = append(schedule.publish, parsed)
+		}
+		if spec := channel.Schedule.FetchTopics; spec != "" {
+			parsed, err := ParseCron(spec)
+			if err != nil {
+				return nil, fmt.Errorf("канал %s: %w", channel.Name, err)
+			}
+			schedule.fetch = append(schedule.fetch, parsed)
+		}
+		d.Logger.Info("Расписание канала %s: %v (%s), случайная задержка до %s", channel.Name, channel.Schedule.Cron, loc, channel.Schedule.Jitter)
+		schedules = append(schedules, schedule)
+	}
+	if len(schedules) == 0 {
+		return nil, fmt.Errorf("ни у одного канала не задано расписание (channels[].schedule.cron)")
+	}
+	return schedules, nil
+}
+
+// nextSlot возвращает самый ранний запланированный слот среди всех каналов.
+func (d *Daemon) nextSlot(schedules []*channelSchedule) (*store.ScheduleSlot, *channelSchedule, error) {
+	var next *store.ScheduleSlot
+	var nextSchedule *channelSchedule
+	for _, schedule := range schedules {
+		for _, kind := range []string{store.SlotTopics, store.SlotPublish} {
+			slot, err := d.plan(schedule, kind)
+			if err != nil {
+				return nil, nil, err
+			}
+			if slot != nil && (next == nil || slot.PlannedAt.Before(next.PlannedAt)) {
+				next, nextSchedule = slot, schedule
+			}
+		}
+	}
+	return next, nextSchedule, nil
+}
+
+// plan находит ближайший невыполненный слот вида kind и сохраняет его со случайной задержкой.
+// Слоты, пропущенные больше чем на missed_window (демон был остановлен), и все пропущенные, кроме последнего,
+// отмечаются как skipped.
+func (d *Daemon) plan(schedule *channelSchedule, kind string) (*store.ScheduleSlot, error) {
+	specs, jitter := schedule.publish, schedule.channel.Schedule.Jitter
+	if kind == store.SlotTopics {
+		specs, jitter = schedule.fetch, 0
+	}
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	missedWindow := d.Config.App.Scheduler.MissedWindow
+	if missedWindow <= 0 {
+		missedWindow = defaultMissedWindow
+	}
+
+	now := time.Now().In(schedule.loc)
+	from := now.Add(-missedWindow)
+	for {
+		slotTime := earliest(specs, from)
+		if slotTime.IsZero() {
+			return nil, nil
+		}
+		from = slotTime
+
+		slot, err := d.Store.GetSlot(schedule.channel.Name, kind, slotTime)
+		if err != nil {
+			return nil, err
+		}
+		if slot == nil {
+			plannedAt := slotTime
+			if jitter > 0 {
+				plannedAt = plannedAt.Add(rand.N(jitter))
+			}
+			slot, err = d.Store.PlanSlot(store.ScheduleSlot{Channel: schedule.channel.Name, Kind: kind, Slot: slotTime, PlannedAt: plannedAt})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if slot.Status != store.SlotPlanned {
+			continue
+		}
+		// Из нескольких пропущенных слотов выполняется только последний, чтобы не выпускать ролики пачкой
+		superseded := !earliest(specs, slotTime).After(now)
+		if now.Sub(slot.PlannedAt) > missedWindow || superseded {
+			d.Logger.Warn("Слот %s канала %s на %s пропущен: демон был остановлен", kind, slot.Channel, slot.PlannedAt.Format(time.DateTime))
+			if err := d.Store.SkipSlot(slot.Channel, kind, slotTime); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return slot, nil
+	}
+}
+
+// earliest возвращает ближайшее срабатывание любого из расписаний после from.
+func earliest(specs []*Schedule, from time.Time) time.Time {
+	var next time.Time
+	for _, spec := range specs {
+		if t := spec.Next(from); !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next
+}
+
+// execute выполняет слот, если его не взял другой процесс, и сохраняет итог.
+func (d *Daemon) execute(ctx context.Context, slot *store.ScheduleSlot, schedule *channelSchedule) {
+	claimed, err := d.Store.ClaimSlot(slot.Channel, slot.Kind, slot.Slot)
+	if err != nil {
+		d.Logger.Error("Не удалось взять слот расписания: %v", err)
+		return
+	}
+	if !claimed {
+		d.Logger.Warn("Слот %s канала %s на %s уже выполняется или выполнен", slot.Kind, slot.Channel, slot.Slot.Format(time.DateTime))
+		return
+	}
+
+	var runID string
+	switch slot.Kind {
+	case store.SlotTopics:
+		d.Logger.Info("Сбор тем для канала %s по расписанию", slot.Channel)
+		_, err = d.Topics.Collect(schedule.channel)
+	case store.SlotPublish:
+		d.Logger.Info("Запуск генерации для канала %s по расписанию", slot.Channel)
+		d.refillTopics(schedule.channel)
+		// Начатый запуск доводится до конца даже при остановке демона, иначе ролик может выйти наполовину опубликованным
+		req := pipeline.Request{Channel: slot.Channel, Review: d.Config.App.Review.Enabled}
+		var result *pipeline.Result
+		result, err = d.Pipeline.Run(context.WithoutCancel(ctx), req)
+		if result != nil {
+			runID = result.RunID
+		}
+		// Слот выполнен: ролик собран и ждет проверки, опубликует его обработчик очереди после одобрения
+		if errors.Is(err, pipeline.ErrAwaitingReview) {
+			_, err = d.Jobs.AwaitReview(req, result)
+		}
+	}
+	if err != nil {
+		d.Logger.Error("Слот %s канала %s завершился ошибкой: %v", slot.Kind, slot.Channel, err)
+	}
+	if err := d.Store.FinishSlot(slot.Channel, slot.Kind, slot.Slot, runID, err); err != nil {
+		d.Logger.Error("Не удалось сохранить итог слота расписания: %v", err)
+	}
+}
+
+// refillTopics собирает темы из источников канала, если его очередь тем пуста.
+func (d *Daemon) refillTopics(channel *config.ChannelConfig) {
+	if len(channel.Topics.Sources) == 0 {
+		return
+	}
+	queued, err := d.Store.ListTopics(channel.Name, store.TopicQueued, 1)
+	if err != nil || len(queued) > 0 {
+		return
+	}
+	d.Logger.Info("Очередь тем канала %s пуста, собираем темы из источников", channel.Name)
+	if _, err := d.Topics.Collect(channel); err != nil {
+		d.Logger.Warn("Не удалось пополнить очередь тем: %v", err)
+	}
+}
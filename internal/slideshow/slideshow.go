@@ -0,0 +1,140 @@
+// internal/slideshow/slideshow.go
+package slideshow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/video"
+	"ai-content-gen/pkg/utils"
+)
+
+// ProviderName записывается в VideoSegment.Provider для сегментов слайд-шоу.
+const ProviderName = "slideshow"
+
+// Generator собирает видеосегменты из неподвижных изображений, когда видеомодель недоступна:
+// изображение берется у нейросети изображений или из локальной папки и анимируется
+// эффектом Кена Бернса.
+type Generator struct {
+	Config *config.AppConfig
+	Images *ai.ImageGenerator
+	Editor *video.VideoEditor
+	Logger *utils.Logger
+
+	assets []string // Изображения из asset_dir, читаются при первом обращении
+}
+
+// NewGenerator создает новый экземпляр Generator.
+// images может быть nil, тогда используются только изображения из asset_dir.
+func NewGenerator(cfg *config.AppConfig, images *ai.ImageGenerator, editor *video.VideoEditor, logger *utils.Logger) *Generator {
+	return &Generator{
+		Config: cfg,
+		Images: images,
+		Editor: editor,
+		Logger: logger,
+	}
+}
+
+// GenerateSegment создает сегмент сцены segmentIndex длительностью duration секунд по промпту prompt.
+// Результат совместим с VideoGenerator.GenerateVideoSegment и сохраняется в той же рабочей директории workDir.
+func (g *Generator) GenerateSegment(prompt string, segmentIndex int, duration float64, workDir string) (*ai.VideoSegment, error) {
+	videoCfg := g.Config.AI.Video
+	if duration <= 0 {
+		duration = videoCfg.Duration.MinScene
+	}
+	if duration <= 0 {
+		duration = 5
+	}
+	width, height, err := video.ParseResolution(videoCfg.Resolution)
+	if err != nil {
+		return nil, err
+	}
+
+	imagePath, err := g.sceneImage(prompt, segmentIndex, workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	motion := video.KenBurnsMotions[(segmentIndex-1)%len(video.KenBurnsMotions)]
+	videoPath := filepath.Join(workDir, fmt.Sprintf("segment_%d.%s", segmentIndex, videoCfg.OutputFormat))
+	if _, err := g.Editor.AnimateImage(imagePath, videoPath, video.KenBurnsOptions{
+		Width:    width,
+		Height:   height,
+		FPS:      videoCfg.FPS,
+		Duration: duration,
+		Zoom:     videoCfg.Slideshow.Zoom,
+		Motion:   motion,
+	}); err != nil {
+		return nil, fmt.Errorf("ошибка анимации изображения для сцены %d: %w", segmentIndex, err)
+	}
+
+	g.Logger.Info("Сегмент слайд-шоу для сцены %d сохранен: %s", segmentIndex, videoPath)
+	return &ai.VideoSegment{
+		Index:    segmentIndex,
+		Path:     videoPath,
+		Provider: ProviderName,
+		Request: ai.VideoGenerationRequest{
+			Prompt:         prompt,
+			Resolution:     videoCfg.Resolution,
+			OutputFormat:   videoCfg.OutputFormat,
+			FPS:            videoCfg.FPS,
+			Duration:       duration,
+			ReferenceImage: imagePath,
+		},
+		GeneratedAt: time.Now(),
+	}, nil
+}
+
+// sceneImage возвращает изображение для сцены. При source=image оно генерируется нейросетью,
+// а при ее недоступности берется из asset_dir; при source=assets — сразу из папки.
+func (g *Generator) sceneImage(prompt string, segmentIndex int, workDir string) (string, error) {
+	cfg := g.Config.AI.Video.Slideshow
+	if cfg.Source != config.SlideshowSourceAssets && g.Images != nil {
+		path, err := g.Images.GenerateImage(prompt, filepath.Join(workDir, fmt.Sprintf("slide_%d.png", segmentIndex)))
+		if err == nil {
+			return path, nil
+		}
+		if cfg.AssetDir == "" {
+			return "", fmt.Errorf("не удалось сгенерировать изображение для сцены %d: %w", segmentIndex, err)
+		}
+		g.Logger.Warn("Не удалось сгенерировать изображение для сцены %d, берем из %s: %v", segmentIndex, cfg.AssetDir, err)
+	}
+	return g.assetImage(segmentIndex)
+}
+
+// assetImage выбирает изображение из asset_dir. Файлы перебираются по кругу в алфавитном порядке,
+// поэтому порядок сцен можно задать именами файлов.
+func (g *Generator) assetImage(segmentIndex int) (string, error) {
+	if g.assets == nil {
+		dir := g.Config.AI.Video.Slideshow.AssetDir
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return "", fmt.Errorf("не удалось прочитать папку изображений %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && isImageFile(entry.Name()) {
+				g.assets = append(g.assets, filepath.Join(dir, entry.Name()))
+			}
+		}
+		sort.Strings(g.assets)
+		if len(g.assets) == 0 {
+			return "", fmt.Errorf("в папке %s нет изображений для слайд-шоу", dir)
+		}
+	}
+	return g.assets[(segmentIndex-1)%len(g.assets)], nil
+}
+
+// isImageFile проверяет расширение файла изображения.
+func isImageFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".webp", ".bmp":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+// internal/store/embeddings.go
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// IdeaEmbedding — векторное представление идеи прошлого запуска.
+type IdeaEmbedding struct {
+	RunID     string
+	Channel   string
+	Idea      string
+	Model     string
+	Vector    []float32
+	CreatedAt time.Time
+}
+
+// SaveIdeaEmbedding сохраняет вектор идеи запуска для последующей проверки на повторы.
+func (s *Store) SaveIdeaEmbedding(embedding IdeaEmbedding) {
+	if s == nil {
+		return
+	}
+	if embedding.CreatedAt.IsZero() {
+		embedding.CreatedAt = time.Now()
+	}
+	s.exec("эмбеддинг идеи", `INSERT OR REPLACE INTO idea_embeddings (run_id, channel, idea, model, vector, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		embedding.RunID, embedding.Channel, embedding.Idea, embedding.Model, encodeVector(embedding.Vector), formatTime(embedding.CreatedAt))
+}
+
+// IdeaEmbeddings возвращает до limit последних векторов идей канала, полученных моделью model.
+// Векторы разных моделей несравнимы, поэтому модель входит в фильтр.
+func (s *Store) IdeaEmbeddings(channel, model string, limit int) ([]IdeaEmbedding, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 200
+	}
+	rows, err := s.db.Query(`SELECT run_id, channel, idea, model, vector, created_at FROM idea_embeddings
+		WHERE channel = ? AND model = ? ORDER BY created_at DESC LIMIT ?`, channel, model, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения эмбеддингов идей: %w", err)
+	}
+	defer rows.Close()
+
+	var embeddings []IdeaEmbedding
+	for rows.Next() {
+		var embedding IdeaEmbedding
+		var vector []byte
+		var createdAt string
+		if err := rows.Scan(&embedding.RunID, &embedding.Channel, &embedding.Idea, &embedding.Model, &vector, &createdAt); err != nil {
+			return nil, fmt.Errorf("ошибка чтения эмбеддингов идей: %w", err)
+		}
+		embedding.Vector = decodeVector(vector)
+		embedding.CreatedAt = parseTime(createdAt)
+		embeddings = append(embeddings, embedding)
+	}
+	return embeddings, rows.Err()
+}
+
+// encodeVector упаковывает вектор в little-endian float32.
+func encodeVector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return data
+}
+
+func decodeVector(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector
+}
@@ -0,0 +1,354 @@
+// internal/store/jobs.go
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Статусы задания в очереди.
+const (
+	JobQueued    = "queued"    // Ждет свободного обработчика
+	JobRunning   = "running"   // Выполняется обработчиком, который держит аренду
+	JobCompleted = "completed" // Ролик готов
+	JobCanceled  = "canceled"  // Отменено вручную
+	JobDead      = "dead"      // Попытки исчерпаны; ждет разбора и ручного повтора
+
+	JobAwaitingReview = "awaiting_review" // Ролик готов и ждет решения проверяющего
+	JobRejected       = "rejected"        // Проверяющий отклонил ролик
+)
+
+// ErrLeaseLost означает, что аренда задания истекла и его мог взять другой обработчик.
+var ErrLeaseLost = errors.New("аренда задания потеряна")
+
+// Job — задание на генерацию ролика в очереди. Задание арендуется обработчиком на время lease;
+// обработчик продлевает аренду, пока работает. Если аренда истекла (процесс упал или завис),
+// задание возвращается в очередь и продолжается с упавшего этапа.
+type Job struct {
+	ID        string
+	Status    string
+	Channel   string
+	Topic     string
+	Style     string
+	Platforms []string
+	PublishAt time.Time // Нулевое — по расписанию платформ
+	Review    bool      // Публиковать только после одобрения проверяющим
+
+	RunID      string
+	Dir        string // Рабочая директория запуска
+	Stage      string
+	Step       int
+	Steps      int
+	RetryStage string // Этап, с которого повторить запуск; пусто — с упавшего
+	// RetryScenes — сцены, которые повтор с этапа segments генерирует заново; остальные сегменты сохраняются
+	RetryScenes []int
+	Idea        string
+	VideoPath   string
+
+	Attempts        int
+	MaxAttempts     int
+	Error           string
+	Worker          string    // Обработчик, который держит аренду
+	LeaseUntil      time.Time // Когда истекает аренда
+	CancelRequested bool      // Отмена запрошена, обработчик прервет запуск
+	AvailableAt     time.Time // Раньше этого времени задание не выдается (пауза перед повтором)
+
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	UpdatedAt  time.Time
+}
+
+// JobResult — итог попытки выполнения задания.
+type JobResult struct {
+	RunID          string
+	Dir            string
+	Topic          string
+	Idea           string
+	VideoPath      string
+	AwaitingReview bool // Ролик готов и остановлен перед публикацией
+}
+
+const jobColumns = `id, status, channel, topic, style, platforms, publish_at, review, run_id, dir, stage, step, steps, retry_stage, retry_scenes, idea, video_path,
+	attempts, max_attempts, error, worker, lease_until, cancel_requested, available_at, created_at, started_at, finished_at, updated_at`
+
+// EnqueueJob добавляет задание в очередь.
+func (s *Store) EnqueueJob(job Job) error {
+	now := time.Now()
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = now
+	}
+	_, err := s.db.Exec(`INSERT INTO jobs (id, status, channel, topic, style, platforms, publish_at, review, max_attempts, available_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID, JobQueued, job.Channel, job.Topic, job.Style, strings.Join(job.Platforms, ","), formatOptionalTime(job.PublishAt),
+		job.Review, job.MaxAttempts, formatTime(now), formatTime(job.CreatedAt), formatTime(now))
+	if err != nil {
+		return fmt.Errorf("ошибка добавления задания в очередь: %w", err)
+	}
+	return nil
+}
+
+// AddReviewJob добавляет задание для ролика, который собран вне очереди и ждет проверки.
+// После одобрения обработчик очереди продолжит запуск job.RunID и опубликует ролик.
+func (s *Store) AddReviewJob(job Job, stage string) error {
+	now := formatTime(time.Now())
+	_, err := s.db.Exec(`INSERT INTO jobs (id, status, channel, topic, style, platforms, publish_at, review, run_id, dir, stage,
+			idea, video_path, max_attempts, available_at, created_at, started_at, finished_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID, JobAwaitingReview, job.Channel, job.Topic, job.Style, strings.Join(job.Platforms, ","), formatOptionalTime(job.PublishAt),
+		job.RunID, job.Dir, stage, job.Idea, job.VideoPath, job.MaxAttempts, now, now, now, now, now)
+	if err != nil {
+		return fmt.Errorf("ошибка добавления ролика на проверку: %w", err)
+	}
+	return nil
+}
+
+// GetJob возвращает задание или nil, если его нет.
+func (s *Store) GetJob(id string) (*Job, error) {
+	job, err := scanJob(s.db.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+// ListJobs возвращает задания со статусом status (пусто — все), новые первыми.
+func (s *Store) ListJobs(status string, limit int) ([]Job, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if status == "" {
+		return s.queryJobs(`SELECT `+jobColumns+` FROM jobs ORDER BY created_at DESC LIMIT ?`, limit)
+	}
+	return s.queryJobs(`SELECT `+jobColumns+` FROM jobs WHERE status = ? ORDER BY created_at DESC LIMIT ?`, status, limit)
+}
+
+// CountJobs возвращает число заданий со статусом status.
+func (s *Store) CountJobs(status string) (int, error) {
+	var count int
+	if err := s.db.QueryRow(`SELECT count(*) FROM jobs WHERE status = ?`, status).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка чтения очереди заданий: %w", err)
+	}
+	return count, nil
+}
+
+// ClaimJob выдает обработчику worker самое старое доступное задание и арендует его на lease.
+// Возвращает nil, если доступных заданий нет. Выдача атомарна: одно задание не достанется двум обработчикам.
+func (s *Store) ClaimJob(worker string, lease time.Duration) (*Job, error) {
+	now := time.Now()
+	job, err := scanJob(s.db.QueryRow(`UPDATE jobs SET status = ?, worker = ?, lease_until = ?, attempts = attempts + 1,
+			started_at = ?, finished_at = '', updated_at = ?
+		WHERE id = (SELECT id FROM jobs WHERE status = ? AND available_at <= ? ORDER BY available_at, created_at LIMIT 1) AND status = ?
+		RETURNING `+jobColumns,
+		JobRunning, worker, formatTime(now.Add(lease)), formatTime(now), formatTime(now), JobQueued, formatTime(now), JobQueued))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выдачи задания из очереди: %w", err)
+	}
+	return job, nil
+}
+
+// HeartbeatJob продлевает аренду задания обработчиком worker на lease.
+// Возвращает, запрошена ли отмена задания, или ErrLeaseLost, если аренда уже не принадлежит обработчику.
+func (s *Store) HeartbeatJob(id, worker string, lease time.Duration) (bool, error) {
+	now := time.Now()
+	var cancelRequested bool
+	err := s.db.QueryRow(`UPDATE jobs SET lease_until = ?, updated_at = ? WHERE id = ? AND worker = ? AND status = ?
+		RETURNING cancel_requested`,
+		formatTime(now.Add(lease)), formatTime(now), id, worker, JobRunning).Scan(&cancelRequested)
+	if err == sql.ErrNoRows {
+		return false, ErrLeaseLost
+	}
+	if err != nil {
+		return false, fmt.Errorf("ошибка продления аренды задания: %w", err)
+	}
+	return cancelRequested, nil
+}
+
+// UpdateJobProgress сохраняет ход выполнения задания.
+func (s *Store) UpdateJobProgress(id, worker, runID, dir, stage string, step, steps int) error {
+	_, err := s.db.Exec(`UPDATE jobs SET run_id = ?, dir = ?, stage = ?, step = ?, steps = ?, updated_at = ?
+		WHERE id = ? AND worker = ? AND status = ?`,
+		runID, dir, stage, step, steps, formatTime(time.Now()), id, worker, JobRunning)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения хода задания: %w", err)
+	}
+	return nil
+}
+
+// FinishJob сохраняет итог попытки. Успешное задание становится completed, остановленное до проверки —
+// awaiting_review, отмененное (или упавшее после запроса на отмену) — canceled.
+// Упавшее задание возвращается в очередь не раньше чем через retryDelay и продолжится с упавшего этапа,
+// а после max_attempts попыток уходит в dead. Возвращает новый статус задания
+// или ErrLeaseLost, если аренда уже не принадлежит обработчику.
+func (s *Store) FinishJob(id, worker string, result JobResult, runErr error, canceled bool, retryDelay time.Duration) (string, error) {
+	now := time.Now()
+	message := ""
+	if runErr != nil {
+		message = runErr.Error()
+	}
+	var status string
+	err := s.db.QueryRow(`UPDATE jobs SET
+			status = CASE
+				WHEN ? THEN ?
+				WHEN ? THEN ?
+				WHEN ? OR cancel_requested THEN ?
+				WHEN attempts >= max_attempts THEN ?
+				ELSE ? END,
+			run_id = CASE WHEN ? = '' THEN run_id ELSE ? END,
+			dir = CASE WHEN ? = '' THEN dir ELSE ? END,
+			topic = CASE WHEN ? = '' THEN topic ELSE ? END,
+			idea = ?, video_path = ?, error = ?, retry_stage = '', retry_scenes = '', worker = '', lease_until = '', cancel_requested = 0,
+			available_at = ?, finished_at = ?, updated_at = ?
+		WHERE id = ? AND worker = ? AND status = ?
+		RETURNING status`,
+		result.AwaitingReview, JobAwaitingReview, runErr == nil, JobCompleted, canceled, JobCanceled, JobDead, JobQueued,
+		result.RunID, result.RunID, result.Dir, result.Dir, result.Topic, result.Topic,
+		result.Idea, result.VideoPath, message,
+		formatTime(now.Add(retryDelay)), formatTime(now), formatTime(now),
+		id, worker, JobRunning).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", ErrLeaseLost
+	}
+	if err != nil {
+		return "", fmt.Errorf("ошибка сохранения итога задания: %w", err)
+	}
+	return status, nil
+}
+
+// ExpireJobLeases возвращает в очередь задания с истекшей арендой: их обработчик упал или завис.
+// Задания, исчерпавшие попытки, уходят в dead. Возвращает число обработанных заданий.
+func (s *Store) ExpireJobLeases() (int64, error) {
+	now := formatTime(time.Now())
+	result, err := s.db.Exec(`UPDATE jobs SET
+			status = CASE WHEN attempts >= max_attempts THEN ? ELSE ? END,
+			error = 'аренда обработчика ' || worker || ' истекла', worker = '', lease_until = '', available_at = ?, updated_at = ?
+		WHERE status = ? AND lease_until < ?`,
+		JobDead, JobQueued, now, now, JobRunning, now)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка проверки аренды заданий: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// CancelJob отменяет задание: ждущее очереди или проверки — сразу, выполняющемуся выставляет запрос на отмену,
+// который обработчик получит при продлении аренды. Возвращает false, если задание уже завершено.
+func (s *Store) CancelJob(id string) (bool, error) {
+	now := formatTime(time.Now())
+	return s.updateJob("отмены задания", `UPDATE jobs SET
+			status = CASE WHEN status = ? THEN status ELSE ? END,
+			cancel_requested = CASE WHEN status = ? THEN 1 ELSE cancel_requested END,
+			finished_at = CASE WHEN status = ? THEN finished_at ELSE ? END,
+			updated_at = ?
+		WHERE id = ? AND status IN (?, ?, ?)`,
+		JobRunning, JobCanceled, JobRunning, JobRunning, now, now, id, JobQueued, JobRunning, JobAwaitingReview)
+}
+
+// ApproveJob возвращает в очередь одобренное проверяющим задание, чтобы обработчик его опубликовал.
+// С publishNow ролик выходит сразу, без отложенной публикации. Возвращает false, если задание не ждет проверки.
+func (s *Store) ApproveJob(id string, publishNow bool) (bool, error) {
+	now := formatTime(time.Now())
+	return s.updateJob("одобрения задания", `UPDATE jobs SET status = ?, publish_at = CASE WHEN ? THEN ? ELSE publish_at END,
+			attempts = 0, error = '', available_at = ?, finished_at = '', updated_at = ?
+		WHERE id = ? AND status = ?`,
+		JobQueued, publishNow, now, now, now, id, JobAwaitingReview)
+}
+
+// RejectJob отмечает задание, ролик которого отклонил проверяющий. Возвращает false, если задание не ждет проверки.
+func (s *Store) RejectJob(id, comment string) (bool, error) {
+	now := formatTime(time.Now())
+	return s.updateJob("отклонения задания", `UPDATE jobs SET status = ?, error = ?, finished_at = ?, updated_at = ? WHERE id = ? AND status = ?`,
+		JobRejected, comment, now, now, id, JobAwaitingReview)
+}
+
+// RetryJob возвращает в очередь отмененное, отклоненное или исчерпавшее попытки задание с новым счетчиком попыток.
+// Запуск продолжится с этапа stage (пусто — с упавшего). Возвращает false, если задание в другом статусе.
+func (s *Store) RetryJob(id, stage string) (bool, error) {
+	now := formatTime(time.Now())
+	return s.updateJob("повтора задания", `UPDATE jobs SET status = ?, retry_stage = ?, retry_scenes = '', attempts = 0, error = '', cancel_requested = 0,
+			available_at = ?, finished_at = '', updated_at = ?
+		WHERE id = ? AND status IN (?, ?, ?)`,
+		JobQueued, stage, now, now, id, JobCanceled, JobDead, JobRejected)
+}
+
+// RegenerateJob возвращает в очередь задание, у которого нужно заново сгенерировать сцены scenes
+// (номера с 1) на этапе stage и пересобрать ролик. Подходит для задания, которое ждет проверки, отклонено, отменено
+// или исчерпало попытки. Возвращает false, если задание в другом статусе.
+func (s *Store) RegenerateJob(id, stage string, scenes []int) (bool, error) {
+	list := make([]string, len(scenes))
+	for i, scene := range scenes {
+		list[i] = strconv.Itoa(scene)
+	}
+	now := formatTime(time.Now())
+	return s.updateJob("перегенерации сцен задания", `UPDATE jobs SET status = ?, retry_stage = ?, retry_scenes = ?, attempts = 0, error = '',
+			cancel_requested = 0, available_at = ?, finished_at = '', updated_at = ?
+		WHERE id = ? AND status IN (?, ?, ?, ?)`,
+		JobQueued, stage, strings.Join(list, ","), now, now, id, JobAwaitingReview, JobRejected, JobCanceled, JobDead)
+}
+
+// updateJob выполняет условное обновление задания и сообщает, затронуло ли оно задание.
+func (s *Store) updateJob(what, query string, args ...interface{}) (bool, error) {
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return false, fmt.Errorf("ошибка %s: %w", what, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка %s: %w", what, err)
+	}
+	return n > 0, nil
+}
+
+func (s *Store) queryJobs(query string, args ...interface{}) ([]Job, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения очереди заданий: %w", err)
+	}
+	defer rows.Close()
+	var jobs []Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения очереди заданий: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+func scanJob(row rowScanner) (*Job, error) {
+	var job Job
+	var platforms, retryScenes, publishAt, leaseUntil, availableAt, createdAt, startedAt, finishedAt, updatedAt string
+	err := row.Scan(&job.ID, &job.Status, &job.Channel, &job.Topic, &job.Style, &platforms, &publishAt, &job.Review,
+		&job.RunID, &job.Dir, &job.Stage, &job.Step, &job.Steps, &job.RetryStage, &retryScenes, &job.Idea, &job.VideoPath,
+		&job.Attempts, &job.MaxAttempts, &job.Error, &job.Worker, &leaseUntil, &job.CancelRequested,
+		&availableAt, &createdAt, &startedAt, &finishedAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if platforms != "" {
+		job.Platforms = strings.Split(platforms, ",")
+	}
+	for _, scene := range strings.Split(retryScenes, ",") {
+		if n, err := strconv.Atoi(scene); err == nil {
+			job.RetryScenes = append(job.RetryScenes, n)
+		}
+	}
+	job.PublishAt, job.LeaseUntil, job.AvailableAt = parseTime(publishAt), parseTime(leaseUntil), parseTime(availableAt)
+	job.CreatedAt, job.StartedAt, job.FinishedAt, job.UpdatedAt = parseTime(createdAt), parseTime(startedAt), parseTime(finishedAt), parseTime(updatedAt)
+	return &job, nil
+}
+
+// formatOptionalTime сохраняет нулевое время пустой строкой.
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return formatTime(t)
+}
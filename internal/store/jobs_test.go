@@ -0,0 +1,213 @@
+// internal/store/jobs_test.go
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ai-content-gen/pkg/utils"
+)
+
+// openTestStore открывает базу истории во временной директории теста.
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "history.db"), utils.NewLogger())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// enqueueTestJob добавляет задание и проверяет ошибку.
+func enqueueTestJob(t *testing.T, s *Store, id string, maxAttempts int) {
+	t.Helper()
+	if err := s.EnqueueJob(Job{ID: id, Channel: "space", MaxAttempts: maxAttempts}); err != nil {
+		t.Fatalf("EnqueueJob(%s): %v", id, err)
+	}
+}
+
+// claimTestJob выдает задание обработчику и проверяет, что выдано ожидаемое.
+func claimTestJob(t *testing.T, s *Store, worker string, lease time.Duration, wantID string) *Job {
+	t.Helper()
+	job, err := s.ClaimJob(worker, lease)
+	if err != nil {
+		t.Fatalf("ClaimJob: %v", err)
+	}
+	switch {
+	case wantID == "" && job != nil:
+		t.Fatalf("ClaimJob выдал %s, ожидалось пусто", job.ID)
+	case wantID != "" && job == nil:
+		t.Fatalf("ClaimJob ничего не выдал, ожидалось %s", wantID)
+	case wantID != "" && job.ID != wantID:
+		t.Fatalf("ClaimJob выдал %s, ожидалось %s", job.ID, wantID)
+	}
+	return job
+}
+
+// getTestJob читает задание и проверяет, что оно есть.
+func getTestJob(t *testing.T, s *Store, id string) *Job {
+	t.Helper()
+	job, err := s.GetJob(id)
+	if err != nil || job == nil {
+		t.Fatalf("GetJob(%s) = %v, %v", id, job, err)
+	}
+	return job
+}
+
+func TestClaimJob(t *testing.T) {
+	s := openTestStore(t)
+	claimTestJob(t, s, "w1", time.Minute, "")
+
+	now := time.Now()
+	enqueueTestJob(t, s, "first", 3)
+	enqueueTestJob(t, s, "second", 3)
+
+	// Задания выдаются в порядке постановки в очередь
+	job := claimTestJob(t, s, "w1", time.Minute, "first")
+	if job.Status != JobRunning || job.Worker != "w1" || job.Attempts != 1 {
+		t.Errorf("выданное задание: статус %s, обработчик %q, попытка %d", job.Status, job.Worker, job.Attempts)
+	}
+	if !job.LeaseUntil.After(now) {
+		t.Errorf("аренда до %v не в будущем", job.LeaseUntil)
+	}
+	// Выданное задание второму обработчику не достается
+	claimTestJob(t, s, "w2", time.Minute, "second")
+	claimTestJob(t, s, "w3", time.Minute, "")
+}
+
+func TestFinishJob(t *testing.T) {
+	runErr := errors.New("видеобэкенд недоступен")
+	tests := []struct {
+		name        string
+		maxAttempts int
+		result      JobResult
+		runErr      error
+		canceled    bool
+		cancelJob   bool // Отмена запрошена через CancelJob во время выполнения
+		retryDelay  time.Duration
+		want        string
+		claimable   bool // Задание сразу снова выдается обработчику
+	}{
+		{name: "успех", maxAttempts: 3, want: JobCompleted},
+		{name: "ждет проверки", maxAttempts: 3, result: JobResult{AwaitingReview: true}, want: JobAwaitingReview},
+		{name: "повтор", maxAttempts: 3, runErr: runErr, want: JobQueued, claimable: true},
+		{name: "повтор после паузы", maxAttempts: 3, runErr: runErr, retryDelay: time.Hour, want: JobQueued},
+		{name: "попытки исчерпаны", maxAttempts: 1, runErr: runErr, want: JobDead},
+		{name: "отменено обработчиком", maxAttempts: 3, runErr: runErr, canceled: true, want: JobCanceled},
+		{name: "отмена запрошена", maxAttempts: 3, runErr: runErr, cancelJob: true, want: JobCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openTestStore(t)
+			enqueueTestJob(t, s, "job", tt.maxAttempts)
+			claimTestJob(t, s, "w1", time.Minute, "job")
+			if tt.cancelJob {
+				if ok, err := s.CancelJob("job"); !ok || err != nil {
+					t.Fatalf("CancelJob = %v, %v", ok, err)
+				}
+			}
+
+			status, err := s.FinishJob("job", "w1", tt.result, tt.runErr, tt.canceled, tt.retryDelay)
+			if err != nil {
+				t.Fatalf("FinishJob: %v", err)
+			}
+			job := getTestJob(t, s, "job")
+			if status != tt.want || job.Status != tt.want {
+				t.Errorf("статус %s (в базе %s), ожидался %s", status, job.Status, tt.want)
+			}
+			if job.Worker != "" || !job.LeaseUntil.IsZero() {
+				t.Errorf("аренда не снята: обработчик %q до %v", job.Worker, job.LeaseUntil)
+			}
+			if tt.runErr != nil && job.Error != tt.runErr.Error() {
+				t.Errorf("ошибка %q, ожидалась %q", job.Error, tt.runErr.Error())
+			}
+
+			wantID := ""
+			if tt.claimable {
+				wantID = "job"
+			}
+			claimTestJob(t, s, "w2", time.Minute, wantID)
+		})
+	}
+}
+
+func TestFinishJobLeaseLost(t *testing.T) {
+	s := openTestStore(t)
+	enqueueTestJob(t, s, "job", 3)
+	claimTestJob(t, s, "w1", time.Minute, "job")
+
+	if _, err := s.FinishJob("job", "w2", JobResult{}, nil, false, 0); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("FinishJob чужим обработчиком: %v, ожидалась ErrLeaseLost", err)
+	}
+	if _, err := s.HeartbeatJob("job", "w2", time.Minute); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("HeartbeatJob чужим обработчиком: %v, ожидалась ErrLeaseLost", err)
+	}
+	if cancel, err := s.HeartbeatJob("job", "w1", time.Minute); cancel || err != nil {
+		t.Errorf("HeartbeatJob = %v, %v", cancel, err)
+	}
+}
+
+func TestExpireJobLeases(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		lease       time.Duration
+		expired     int64
+		want        string
+	}{
+		{name: "аренда действует", maxAttempts: 3, lease: time.Minute, expired: 0, want: JobRunning},
+		{name: "возврат в очередь", maxAttempts: 3, lease: -time.Second, expired: 1, want: JobQueued},
+		{name: "попытки исчерпаны", maxAttempts: 1, lease: -time.Second, expired: 1, want: JobDead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openTestStore(t)
+			enqueueTestJob(t, s, "job", tt.maxAttempts)
+			claimTestJob(t, s, "w1", tt.lease, "job")
+
+			expired, err := s.ExpireJobLeases()
+			if err != nil {
+				t.Fatalf("ExpireJobLeases: %v", err)
+			}
+			if expired != tt.expired {
+				t.Errorf("обработано %d заданий, ожидалось %d", expired, tt.expired)
+			}
+			if job := getTestJob(t, s, "job"); job.Status != tt.want {
+				t.Errorf("статус %s, ожидался %s", job.Status, tt.want)
+			}
+			if tt.expired == 0 {
+				return
+			}
+			// Обработчик с истекшей арендой больше не может сохранить итог
+			if _, err := s.FinishJob("job", "w1", JobResult{}, nil, false, 0); !errors.Is(err, ErrLeaseLost) {
+				t.Errorf("FinishJob после истечения аренды: %v, ожидалась ErrLeaseLost", err)
+			}
+		})
+	}
+}
+
+func TestRetryDeadJob(t *testing.T) {
+	s := openTestStore(t)
+	enqueueTestJob(t, s, "job", 1)
+	claimTestJob(t, s, "w1", time.Minute, "job")
+	if status, err := s.FinishJob("job", "w1", JobResult{}, errors.New("сбой"), false, 0); status != JobDead || err != nil {
+		t.Fatalf("FinishJob = %s, %v", status, err)
+	}
+	claimTestJob(t, s, "w1", time.Minute, "")
+
+	// Повтор возможен только из конечных статусов
+	if ok, err := s.RetryJob("job", "assemble"); !ok || err != nil {
+		t.Fatalf("RetryJob = %v, %v", ok, err)
+	}
+	if ok, err := s.RetryJob("job", ""); ok || err != nil {
+		t.Errorf("повторный RetryJob ждущего задания = %v, %v", ok, err)
+	}
+
+	job := claimTestJob(t, s, "w2", time.Minute, "job")
+	if job.Attempts != 1 || job.RetryStage != "assemble" || job.Error != "" {
+		t.Errorf("после повтора: попытка %d, этап %q, ошибка %q", job.Attempts, job.RetryStage, job.Error)
+	}
+}
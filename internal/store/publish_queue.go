@@ -0,0 +1,158 @@
+// internal/store/publish_queue.go
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Статусы отложенной публикации.
+const (
+	PublishPending   = "pending"   // Ждет времени публикации
+	PublishUploading = "uploading" // Загружается
+	PublishDone      = "published" // Опубликована
+	PublishFailed    = "failed"    // Загрузка завершилась ошибкой
+	PublishCanceled  = "canceled"  // Отменена вручную
+)
+
+// ScheduledPublication — ролик в локальной очереди публикаций для платформ без собственной отложенной публикации.
+type ScheduledPublication struct {
+	ID          int64
+	RunID       string
+	Channel     string
+	Platform    string
+	Path        string
+	Title       string
+	Description string
+	Tags        string
+	Thumbnail   string
+	PublishAt   time.Time
+	Status      string
+	URL         string
+	Error       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+const publishQueueColumns = `id, run_id, channel, platform, path, title, description, tags, thumbnail, publish_at, status, url, error, created_at, updated_at`
+
+// QueuePublication ставит ролик в очередь публикаций и возвращает идентификатор записи.
+func (s *Store) QueuePublication(publication ScheduledPublication) (int64, error) {
+	now := formatTime(time.Now())
+	result, err := s.db.Exec(`INSERT INTO publish_queue (run_id, channel, platform, path, title, description, tags, thumbnail, publish_at, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		publication.RunID, publication.Channel, publication.Platform, publication.Path, publication.Title, publication.Description,
+		publication.Tags, publication.Thumbnail, formatTime(publication.PublishAt), PublishPending, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка добавления в очередь публикаций: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// DuePublications возвращает ожидающие публикации, время которых наступило к now.
+func (s *Store) DuePublications(now time.Time) ([]ScheduledPublication, error) {
+	return s.queryPublications(`SELECT `+publishQueueColumns+` FROM publish_queue WHERE status = ? AND publish_at <= ? ORDER BY publish_at`,
+		PublishPending, formatTime(now))
+}
+
+// NextPublishAt возвращает время ближайшей ожидающей публикации или нулевое время, если очередь пуста.
+func (s *Store) NextPublishAt() (time.Time, error) {
+	var publishAt sql.NullString
+	if err := s.db.QueryRow(`SELECT min(publish_at) FROM publish_queue WHERE status = ?`, PublishPending).Scan(&publishAt); err != nil {
+		return time.Time{}, fmt.Errorf("ошибка чтения очереди публикаций: %w", err)
+	}
+	return parseTime(publishAt.String), nil
+}
+
+// ListPublications возвращает записи очереди публикаций со статусом status (пусто — все), ближайшие первыми.
+func (s *Store) ListPublications(status string, limit int) ([]ScheduledPublication, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if status == "" {
+		return s.queryPublications(`SELECT `+publishQueueColumns+` FROM publish_queue ORDER BY publish_at DESC LIMIT ?`, limit)
+	}
+	return s.queryPublications(`SELECT `+publishQueueColumns+` FROM publish_queue WHERE status = ? ORDER BY publish_at LIMIT ?`, status, limit)
+}
+
+// ClaimPublication переводит ожидающую публикацию в uploading. Возвращает false, если ее уже взял другой процесс.
+func (s *Store) ClaimPublication(id int64) (bool, error) {
+	return s.updatePublication(id, PublishPending, PublishUploading, "", "")
+}
+
+// FinishPublication сохраняет результат загрузки из очереди.
+func (s *Store) FinishPublication(id int64, url string, uploadErr error) error {
+	status, message := PublishDone, ""
+	if uploadErr != nil {
+		status, message = PublishFailed, uploadErr.Error()
+	}
+	_, err := s.updatePublication(id, PublishUploading, status, url, message)
+	return err
+}
+
+// CancelPublication отменяет ожидающую публикацию.
+func (s *Store) CancelPublication(id int64) error {
+	ok, err := s.updatePublication(id, PublishPending, PublishCanceled, "", "")
+	if err == nil && !ok {
+		err = fmt.Errorf("публикация %d не найдена или уже не ожидает", id)
+	}
+	return err
+}
+
+// RetryPublication возвращает неудавшуюся публикацию в очередь с временем публикации publishAt.
+func (s *Store) RetryPublication(id int64, publishAt time.Time) error {
+	result, err := s.db.Exec(`UPDATE publish_queue SET status = ?, publish_at = ?, error = '', updated_at = ? WHERE id = ? AND status = ?`,
+		PublishPending, formatTime(publishAt), formatTime(time.Now()), id, PublishFailed)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления очереди публикаций: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("публикация %d не найдена или не завершилась ошибкой", id)
+	}
+	return nil
+}
+
+// InterruptUploadingPublications отмечает как failed загрузки, прерванные остановкой процесса.
+// Повторно они не выполняются автоматически: ролик мог успеть опубликоваться.
+func (s *Store) InterruptUploadingPublications() (int64, error) {
+	result, err := s.db.Exec(`UPDATE publish_queue SET status = ?, error = ?, updated_at = ? WHERE status = ?`,
+		PublishFailed, "загрузка прервана остановкой процесса", formatTime(time.Now()), PublishUploading)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка обновления очереди публикаций: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (s *Store) updatePublication(id int64, from, to, url, message string) (bool, error) {
+	result, err := s.db.Exec(`UPDATE publish_queue SET status = ?, url = ?, error = ?, updated_at = ? WHERE id = ? AND status = ?`,
+		to, url, message, formatTime(time.Now()), id, from)
+	if err != nil {
+		return false, fmt.Errorf("ошибка обновления очереди публикаций: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка обновления очереди публикаций: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (s *Store) queryPublications(query string, args ...interface{}) ([]ScheduledPublication, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения очереди публикаций: %w", err)
+	}
+	defer rows.Close()
+	var publications []ScheduledPublication
+	for rows.Next() {
+		var p ScheduledPublication
+		var publishAt, createdAt, updatedAt string
+		if err := rows.Scan(&p.ID, &p.RunID, &p.Channel, &p.Platform, &p.Path, &p.Title, &p.Description, &p.Tags, &p.Thumbnail,
+			&publishAt, &p.Status, &p.URL, &p.Error, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка чтения очереди публикаций: %w", err)
+		}
+		p.PublishAt, p.CreatedAt, p.UpdatedAt = parseTime(publishAt), parseTime(createdAt), parseTime(updatedAt)
+		publications = append(publications, p)
+	}
+	return publications, rows.Err()
+}
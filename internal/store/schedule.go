@@ -0,0 +1,108 @@
+// internal/store/schedule.go
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Виды слотов расписания.
+const (
+	SlotPublish = "publish" // Запуск генерации и публикации
+	SlotTopics  = "topics"  // Сбор тем из источников
+)
+
+// Статусы слота расписания.
+const (
+	SlotPlanned     = "planned"     // Время запуска выбрано, запуск еще не начат
+	SlotRunning     = "running"     // Запуск выполняется
+	SlotDone        = "done"        // Запуск завершен
+	SlotFailed      = "failed"      // Запуск завершился ошибкой
+	SlotInterrupted = "interrupted" // Процесс остановился во время запуска; повторно не выполняется, чтобы не опубликовать ролик дважды
+	SlotSkipped     = "skipped"     // Слот пропущен: демон был остановлен дольше missed_window
+)
+
+// ScheduleSlot — одно срабатывание расписания канала.
+type ScheduleSlot struct {
+	Channel   string
+	Kind      string
+	Slot      time.Time // Время по cron-выражению, ключ слота
+	PlannedAt time.Time // Фактическое время запуска с учетом случайной задержки
+	Status    string
+	RunID     string
+	Error     string
+	UpdatedAt time.Time
+}
+
+// PlanSlot сохраняет слот со статусом planned, если его еще нет, и возвращает сохраненный слот.
+// Для уже запланированного слота возвращается прежнее время запуска, поэтому после перезапуска задержка не выбирается заново.
+func (s *Store) PlanSlot(slot ScheduleSlot) (*ScheduleSlot, error) {
+	_, err := s.db.Exec(`INSERT OR IGNORE INTO schedule_slots (channel, kind, slot, planned_at, status, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		slot.Channel, slot.Kind, formatTime(slot.Slot), formatTime(slot.PlannedAt), SlotPlanned, formatTime(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения слота расписания: %w", err)
+	}
+	return s.GetSlot(slot.Channel, slot.Kind, slot.Slot)
+}
+
+// GetSlot возвращает слот расписания или nil, если его нет.
+func (s *Store) GetSlot(channel, kind string, slot time.Time) (*ScheduleSlot, error) {
+	row := s.db.QueryRow(`SELECT channel, kind, slot, planned_at, status, run_id, error, updated_at FROM schedule_slots
+		WHERE channel = ? AND kind = ? AND slot = ?`, channel, kind, formatTime(slot))
+	var result ScheduleSlot
+	var slotTime, plannedAt, updatedAt string
+	err := row.Scan(&result.Channel, &result.Kind, &slotTime, &plannedAt, &result.Status, &result.RunID, &result.Error, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения слота расписания: %w", err)
+	}
+	result.Slot, result.PlannedAt, result.UpdatedAt = parseTime(slotTime), parseTime(plannedAt), parseTime(updatedAt)
+	return &result, nil
+}
+
+// ClaimSlot переводит запланированный слот в running. Возвращает false, если слот уже взят или завершен.
+func (s *Store) ClaimSlot(channel, kind string, slot time.Time) (bool, error) {
+	return s.updateSlot(channel, kind, slot, SlotPlanned, SlotRunning, "", "")
+}
+
+// SkipSlot отмечает запланированный слот как пропущенный.
+func (s *Store) SkipSlot(channel, kind string, slot time.Time) error {
+	_, err := s.updateSlot(channel, kind, slot, SlotPlanned, SlotSkipped, "", "")
+	return err
+}
+
+// FinishSlot сохраняет итог выполненного слота.
+func (s *Store) FinishSlot(channel, kind string, slot time.Time, runID string, runErr error) error {
+	status, message := SlotDone, ""
+	if runErr != nil {
+		status, message = SlotFailed, runErr.Error()
+	}
+	_, err := s.updateSlot(channel, kind, slot, SlotRunning, status, runID, message)
+	return err
+}
+
+// InterruptRunningSlots отмечает слоты, которые остались в running после остановки процесса.
+func (s *Store) InterruptRunningSlots() (int64, error) {
+	result, err := s.db.Exec(`UPDATE schedule_slots SET status = ?, updated_at = ? WHERE status = ?`, SlotInterrupted, formatTime(time.Now()), SlotRunning)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка обновления слотов расписания: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (s *Store) updateSlot(channel, kind string, slot time.Time, from, to, runID, message string) (bool, error) {
+	result, err := s.db.Exec(`UPDATE schedule_slots SET status = ?, run_id = ?, error = ?, updated_at = ?
+		WHERE channel = ? AND kind = ? AND slot = ? AND status = ?`,
+		to, runID, message, formatTime(time.Now()), channel, kind, formatTime(slot), from)
+	if err != nil {
+		return false, fmt.Errorf("ошибка обновления слота расписания: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка обновления слота расписания: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,510 @@
+// internal/store/store.go
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ai-content-gen/pkg/utils"
+
+	_ "modernc.org/sqlite" // Драйвер SQLite на чистом Go, без cgo
+)
+
+// Store — история контента во встроенной базе SQLite: запуски и их темы, сценарии,
+// готовые ролики и публикации по каналам. Методы записи безопасны для nil,
+// чтобы недоступная история не останавливала генерацию.
+type Store struct {
+	db     *sql.DB
+	Logger *utils.Logger
+}
+
+// Статусы запуска.
+const (
+	RunRunning        = "running"
+	RunCompleted      = "completed"
+	RunFailed         = "failed"
+	RunAwaitingReview = "awaiting_review" // Ролик готов и ждет решения проверяющего
+)
+
+// Run — один запуск конвейера.
+type Run struct {
+	ID         string
+	Channel    string
+	Topic      string
+	Idea       string
+	Status     string
+	Error      string
+	Workspace  string // Рабочая директория запуска
+	StartedAt  time.Time
+	FinishedAt time.Time // Нулевое значение, если запуск не завершен
+}
+
+// Script — сценарий, сгенерированный в запуске.
+type Script struct {
+	RunID        string
+	Idea         string
+	Hook         string
+	CallToAction string
+	Content      string // Полный ответ текстовой модели
+	CreatedAt    time.Time
+}
+
+// Render — готовый файл ролика (основной или вариант кодирования).
+type Render struct {
+	RunID     string
+	Name      string // final или имя варианта из renditions
+	Path      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Publication — результат публикации на платформе.
+type Publication struct {
+	RunID       string
+	Platform    string
+	Path        string
+	URL         string
+	Error       string
+	PublishedAt time.Time
+}
+
+// RunDetails объединяет запуск со всеми связанными записями.
+type RunDetails struct {
+	Run
+	Script       *Script
+	Renders      []Render
+	Publications []Publication
+}
+
+// RunFilter ограничивает выборку запусков.
+type RunFilter struct {
+	Channel string // Пусто — все каналы
+	Query   string // Подстрока в теме, идее или тексте сценария
+	Limit   int    // 0 — 20 последних
+}
+
+// Open открывает (и при необходимости создает) базу истории по пути path.
+func Open(path string, logger *utils.Logger) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("не удалось создать директорию базы истории: %w", err)
+	}
+	// WAL и busy_timeout позволяют нескольким процессам (бот, history, воркеры) работать с базой одновременно
+	dsn := fmt.Sprintf("file:%s?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)", filepath.ToSlash(path))
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть базу истории %s: %w", path, err)
+	}
+	s := &Store{db: db, Logger: logger}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close закрывает базу.
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// migrate создает таблицы, если их еще нет.
+func (s *Store) migrate() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS runs (
+			id TEXT PRIMARY KEY,
+			channel TEXT NOT NULL,
+			topic TEXT NOT NULL,
+			idea TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			workspace TEXT NOT NULL DEFAULT '',
+			started_at TEXT NOT NULL,
+			finished_at TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS runs_channel_started ON runs(channel, started_at)`,
+		`CREATE TABLE IF NOT EXISTS scripts (
+			run_id TEXT PRIMARY KEY REFERENCES runs(id) ON DELETE CASCADE,
+			idea TEXT NOT NULL,
+			hook TEXT NOT NULL DEFAULT '',
+			call_to_action TEXT NOT NULL DEFAULT '',
+			content TEXT NOT NULL,
+			created_at TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS renders (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			path TEXT NOT NULL,
+			size INTEGER NOT NULL DEFAULT 0,
+			created_at TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS publications (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+			platform TEXT NOT NULL,
+			path TEXT NOT NULL DEFAULT '',
+			url TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			published_at TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS idea_embeddings (
+			run_id TEXT PRIMARY KEY REFERENCES runs(id) ON DELETE CASCADE,
+			channel TEXT NOT NULL,
+			idea TEXT NOT NULL,
+			model TEXT NOT NULL,
+			vector BLOB NOT NULL,
+			created_at TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idea_embeddings_channel ON idea_embeddings(channel, model, created_at)`,
+		`CREATE TABLE IF NOT EXISTS topic_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			channel TEXT NOT NULL,
+			topic TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			link TEXT NOT NULL DEFAULT '',
+			score REAL NOT NULL DEFAULT 0,
+			status TEXT NOT NULL,
+			run_id TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL,
+			used_at TEXT NOT NULL DEFAULT '',
+			UNIQUE (channel, topic)
+		)`,
+		`CREATE INDEX IF NOT EXISTS topic_queue_next ON topic_queue(channel, status, score)`,
+		`CREATE TABLE IF NOT EXISTS schedule_slots (
+			channel TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			slot TEXT NOT NULL,
+			planned_at TEXT NOT NULL,
+			status TEXT NOT NULL,
+			run_id TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			updated_at TEXT NOT NULL,
+			PRIMARY KEY (channel, kind, slot)
+		)`,
+		`CREATE TABLE IF NOT EXISTS publish_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id TEXT NOT NULL,
+			channel TEXT NOT NULL DEFAULT '',
+			platform TEXT NOT NULL,
+			path TEXT NOT NULL,
+			title TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			tags TEXT NOT NULL DEFAULT '',
+			thumbnail TEXT NOT NULL DEFAULT '',
+			publish_at TEXT NOT NULL,
+			status TEXT NOT NULL,
+			url TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS publish_queue_due ON publish_queue(status, publish_at)`,
+		`CREATE TABLE IF NOT EXISTS jobs (
+			id TEXT PRIMARY KEY,
+			status TEXT NOT NULL,
+			channel TEXT NOT NULL DEFAULT '',
+			topic TEXT NOT NULL DEFAULT '',
+			style TEXT NOT NULL DEFAULT '',
+			platforms TEXT NOT NULL DEFAULT '',
+			publish_at TEXT NOT NULL DEFAULT '',
+			review INTEGER NOT NULL DEFAULT 0,
+			run_id TEXT NOT NULL DEFAULT '',
+			dir TEXT NOT NULL DEFAULT '',
+			stage TEXT NOT NULL DEFAULT '',
+			step INTEGER NOT NULL DEFAULT 0,
+			steps INTEGER NOT NULL DEFAULT 0,
+			retry_stage TEXT NOT NULL DEFAULT '',
+			retry_scenes TEXT NOT NULL DEFAULT '',
+			idea TEXT NOT NULL DEFAULT '',
+			video_path TEXT NOT NULL DEFAULT '',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			max_attempts INTEGER NOT NULL DEFAULT 1,
+			error TEXT NOT NULL DEFAULT '',
+			worker TEXT NOT NULL DEFAULT '',
+			lease_until TEXT NOT NULL DEFAULT '',
+			cancel_requested INTEGER NOT NULL DEFAULT 0,
+			available_at TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			started_at TEXT NOT NULL DEFAULT '',
+			finished_at TEXT NOT NULL DEFAULT '',
+			updated_at TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS jobs_available ON jobs(status, available_at)`,
+	}
+	for _, stmt := range statements {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("ошибка миграции базы истории: %w", err)
+		}
+	}
+	// Столбцы, добавленные в уже существующие таблицы
+	columns := []struct{ table, column, definition string }{
+		{"jobs", "review", "INTEGER NOT NULL DEFAULT 0"},
+		{"jobs", "retry_scenes", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, c := range columns {
+		if err := s.addColumn(c.table, c.column, c.definition); err != nil {
+			return fmt.Errorf("ошибка миграции базы истории: %w", err)
+		}
+	}
+	return nil
+}
+
+// addColumn добавляет столбец в таблицу, если его еще нет.
+func (s *Store) addColumn(table, column, definition string) error {
+	rows, err := s.db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = s.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
+// StartRun регистрирует новый запуск.
+func (s *Store) StartRun(run Run) {
+	if s == nil {
+		return
+	}
+	if run.Status == "" {
+		run.Status = RunRunning
+	}
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+	s.exec("запуск", `INSERT INTO runs (id, channel, topic, idea, status, workspace, started_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		run.ID, run.Channel, run.Topic, run.Idea, run.Status, run.Workspace, formatTime(run.StartedAt))
+}
+
+// FinishRun отмечает завершение запуска; runErr == nil означает успех.
+func (s *Store) FinishRun(runID string, runErr error) {
+	if s == nil {
+		return
+	}
+	status, message := RunCompleted, ""
+	if runErr != nil {
+		status, message = RunFailed, runErr.Error()
+	}
+	s.exec("завершение запуска", `UPDATE runs SET status = ?, error = ?, finished_at = ? WHERE id = ?`,
+		status, message, formatTime(time.Now()), runID)
+}
+
+// ResumeRun возвращает запуск в состояние running перед повтором его этапов.
+func (s *Store) ResumeRun(runID string) {
+	if s == nil {
+		return
+	}
+	s.exec("повтор запуска", `UPDATE runs SET status = ?, error = '', finished_at = '' WHERE id = ?`, RunRunning, runID)
+}
+
+// PauseRun отмечает, что запуск остановлен перед публикацией до решения проверяющего.
+func (s *Store) PauseRun(runID string) {
+	if s == nil {
+		return
+	}
+	s.exec("остановка запуска", `UPDATE runs SET status = ?, error = '', finished_at = ? WHERE id = ?`,
+		RunAwaitingReview, formatTime(time.Now()), runID)
+}
+
+// SaveScript сохраняет сценарий запуска и его идею.
+func (s *Store) SaveScript(script Script) {
+	if s == nil {
+		return
+	}
+	if script.CreatedAt.IsZero() {
+		script.CreatedAt = time.Now()
+	}
+	s.exec("сценарий", `INSERT OR REPLACE INTO scripts (run_id, idea, hook, call_to_action, content, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		script.RunID, script.Idea, script.Hook, script.CallToAction, script.Content, formatTime(script.CreatedAt))
+	s.exec("идея запуска", `UPDATE runs SET idea = ? WHERE id = ?`, script.Idea, script.RunID)
+}
+
+// AddRender сохраняет готовый файл ролика.
+func (s *Store) AddRender(render Render) {
+	if s == nil {
+		return
+	}
+	if render.CreatedAt.IsZero() {
+		render.CreatedAt = time.Now()
+	}
+	if render.Size == 0 {
+		if info, err := os.Stat(render.Path); err == nil {
+			render.Size = info.Size()
+		}
+	}
+	s.exec("ролик", `INSERT INTO renders (run_id, name, path, size, created_at) VALUES (?, ?, ?, ?, ?)`,
+		render.RunID, render.Name, render.Path, render.Size, formatTime(render.CreatedAt))
+}
+
+// AddPublication сохраняет результат публикации.
+func (s *Store) AddPublication(publication Publication) {
+	if s == nil {
+		return
+	}
+	if publication.PublishedAt.IsZero() {
+		publication.PublishedAt = time.Now()
+	}
+	s.exec("публикация", `INSERT INTO publications (run_id, platform, path, url, error, published_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		publication.RunID, publication.Platform, publication.Path, publication.URL, publication.Error, formatTime(publication.PublishedAt))
+}
+
+// exec выполняет запись и при ошибке только предупреждает: история не должна прерывать генерацию.
+func (s *Store) exec(what, query string, args ...interface{}) {
+	if _, err := s.db.Exec(query, args...); err != nil {
+		s.Logger.Warn("Не удалось сохранить в историю (%s): %v", what, err)
+	}
+}
+
+// ListRuns возвращает запуски, начиная с последних.
+// Поиск по тексту выполняется в Go: lower() и LIKE в SQLite не учитывают регистр кириллицы.
+func (s *Store) ListRuns(filter RunFilter) ([]Run, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	query := `SELECT r.id, r.channel, r.topic, r.idea, r.status, r.error, r.workspace, r.started_at, r.finished_at, coalesce(sc.content, '')
+		FROM runs r LEFT JOIN scripts sc ON sc.run_id = r.id`
+	var args []interface{}
+	if filter.Channel != "" {
+		query += " WHERE r.channel = ?"
+		args = append(args, filter.Channel)
+	}
+	query += " ORDER BY r.started_at DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения истории запусков: %w", err)
+	}
+	defer rows.Close()
+
+	needle := strings.ToLower(strings.TrimSpace(filter.Query))
+	var runs []Run
+	for rows.Next() && len(runs) < limit {
+		var content string
+		run, err := scanRun(rows, &content)
+		if err != nil {
+			return nil, err
+		}
+		if needle != "" && !strings.Contains(strings.ToLower(run.Topic+"\n"+run.Idea+"\n"+content), needle) {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// GetRun возвращает запуск со сценарием, роликами и публикациями.
+// id может быть префиксом идентификатора, если он однозначен.
+func (s *Store) GetRun(id string) (*RunDetails, error) {
+	rows, err := s.db.Query(`SELECT id, channel, topic, idea, status, error, workspace, started_at, finished_at
+		FROM runs WHERE id LIKE ? ORDER BY started_at DESC LIMIT 2`, id+"%")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения запуска %s: %w", id, err)
+	}
+	var matches []Run
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		matches = append(matches, run)
+	}
+	rows.Close()
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("запуск %s не найден", id)
+	case 2:
+		return nil, fmt.Errorf("идентификатор %s неоднозначен, уточните его", id)
+	}
+
+	details := &RunDetails{Run: matches[0]}
+	runID := details.ID
+
+	var script Script
+	var createdAt string
+	err = s.db.QueryRow(`SELECT run_id, idea, hook, call_to_action, content, created_at FROM scripts WHERE run_id = ?`, runID).
+		Scan(&script.RunID, &script.Idea, &script.Hook, &script.CallToAction, &script.Content, &createdAt)
+	switch {
+	case err == nil:
+		script.CreatedAt = parseTime(createdAt)
+		details.Script = &script
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("ошибка чтения сценария запуска %s: %w", runID, err)
+	}
+
+	renderRows, err := s.db.Query(`SELECT run_id, name, path, size, created_at FROM renders WHERE run_id = ? ORDER BY id`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения роликов запуска %s: %w", runID, err)
+	}
+	defer renderRows.Close()
+	for renderRows.Next() {
+		var render Render
+		if err := renderRows.Scan(&render.RunID, &render.Name, &render.Path, &render.Size, &createdAt); err != nil {
+			return nil, fmt.Errorf("ошибка чтения роликов запуска %s: %w", runID, err)
+		}
+		render.CreatedAt = parseTime(createdAt)
+		details.Renders = append(details.Renders, render)
+	}
+
+	publicationRows, err := s.db.Query(`SELECT run_id, platform, path, url, error, published_at FROM publications WHERE run_id = ? ORDER BY id`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения публикаций запуска %s: %w", runID, err)
+	}
+	defer publicationRows.Close()
+	for publicationRows.Next() {
+		var publication Publication
+		var publishedAt string
+		if err := publicationRows.Scan(&publication.RunID, &publication.Platform, &publication.Path, &publication.URL, &publication.Error, &publishedAt); err != nil {
+			return nil, fmt.Errorf("ошибка чтения публикаций запуска %s: %w", runID, err)
+		}
+		publication.PublishedAt = parseTime(publishedAt)
+		details.Publications = append(details.Publications, publication)
+	}
+	return details, nil
+}
+
+// scanRun читает строку таблицы runs; extra — дополнительные столбцы запроса после основных.
+func scanRun(rows *sql.Rows, extra ...interface{}) (Run, error) {
+	var run Run
+	var startedAt, finishedAt string
+	dest := append([]interface{}{&run.ID, &run.Channel, &run.Topic, &run.Idea, &run.Status, &run.Error, &run.Workspace, &startedAt, &finishedAt}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return run, fmt.Errorf("ошибка чтения запуска: %w", err)
+	}
+	run.StartedAt, run.FinishedAt = parseTime(startedAt), parseTime(finishedAt)
+	return run, nil
+}
+
+// timeLayout — RFC 3339 в UTC с фиксированной точностью: так время сортируется как текст и читается в sqlite3.
+const timeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.Local()
+}
@@ -0,0 +1,164 @@
+// internal/store/topics.go
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Статусы темы в очереди.
+const (
+	TopicQueued   = "queued"   // Ждет генерации
+	TopicUsed     = "used"     // Взята запуском
+	TopicRejected = "rejected" // Отклонена вручную
+)
+
+// QueuedTopic — тема в очереди канала.
+type QueuedTopic struct {
+	ID        int64
+	Channel   string
+	Topic     string
+	Source    string  // Имя источника из конфигурации или "manual"
+	Link      string  // Ссылка на исходную запись ленты
+	Score     float64 // Оценка текстовой модели, 0-10
+	Status    string
+	RunID     string // Запуск, который взял тему
+	CreatedAt time.Time
+	UsedAt    time.Time
+}
+
+// EnqueueTopic ставит тему в очередь канала. Возвращает false, если такая тема
+// у канала уже была (в очереди, использована или отклонена).
+func (s *Store) EnqueueTopic(topic QueuedTopic) (bool, error) {
+	if topic.CreatedAt.IsZero() {
+		topic.CreatedAt = time.Now()
+	}
+	result, err := s.db.Exec(`INSERT OR IGNORE INTO topic_queue (channel, topic, source, link, score, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		topic.Channel, strings.TrimSpace(topic.Topic), topic.Source, topic.Link, topic.Score, TopicQueued, formatTime(topic.CreatedAt))
+	if err != nil {
+		return false, fmt.Errorf("ошибка добавления темы в очередь: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка добавления темы в очередь: %w", err)
+	}
+	return inserted > 0, nil
+}
+
+// TopicExists сообщает, встречалась ли тема у канала, чтобы не оценивать ее повторно.
+func (s *Store) TopicExists(channel, topic string) (bool, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT count(*) FROM topic_queue WHERE channel = ? AND topic = ?`, channel, strings.TrimSpace(topic)).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("ошибка чтения очереди тем: %w", err)
+	}
+	return count > 0, nil
+}
+
+// NextTopic берет из очереди канала тему с наибольшей оценкой и отмечает ее как использованную запуском runID.
+// Возвращает nil, если очередь пуста.
+func (s *Store) NextTopic(channel, runID string) (*QueuedTopic, error) {
+	if s == nil {
+		return nil, nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения очереди тем: %w", err)
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow(`SELECT id, channel, topic, source, link, score, status, run_id, created_at, used_at FROM topic_queue
+		WHERE channel = ? AND status = ? ORDER BY score DESC, created_at LIMIT 1`, channel, TopicQueued)
+	topic, err := scanTopic(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	topic.Status, topic.RunID, topic.UsedAt = TopicUsed, runID, time.Now()
+	if _, err := tx.Exec(`UPDATE topic_queue SET status = ?, run_id = ?, used_at = ? WHERE id = ?`,
+		topic.Status, topic.RunID, formatTime(topic.UsedAt), topic.ID); err != nil {
+		return nil, fmt.Errorf("ошибка обновления очереди тем: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("ошибка обновления очереди тем: %w", err)
+	}
+	return topic, nil
+}
+
+// ReleaseTopic возвращает тему в очередь, например если запуск с ней завершился ошибкой.
+func (s *Store) ReleaseTopic(id int64) {
+	if s == nil {
+		return
+	}
+	s.exec("возврат темы в очередь", `UPDATE topic_queue SET status = ?, run_id = '', used_at = '' WHERE id = ?`, TopicQueued, id)
+}
+
+// SetTopicStatus меняет статус темы (например, отклоняет ее вручную).
+func (s *Store) SetTopicStatus(id int64, status string) error {
+	result, err := s.db.Exec(`UPDATE topic_queue SET status = ? WHERE id = ?`, status, id)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления темы %d: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("тема %d не найдена", id)
+	}
+	return nil
+}
+
+// ListTopics возвращает темы канала с указанным статусом (пусто — все), лучшие первыми.
+func (s *Store) ListTopics(channel, status string, limit int) ([]QueuedTopic, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	query := `SELECT id, channel, topic, source, link, score, status, run_id, created_at, used_at FROM topic_queue WHERE 1 = 1`
+	var args []interface{}
+	if channel != "" {
+		query += " AND channel = ?"
+		args = append(args, channel)
+	}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY status, score DESC, created_at LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения очереди тем: %w", err)
+	}
+	defer rows.Close()
+	var topics []QueuedTopic
+	for rows.Next() {
+		topic, err := scanTopic(rows)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, *topic)
+	}
+	return topics, rows.Err()
+}
+
+// rowScanner — общий интерфейс sql.Row и sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTopic(row rowScanner) (*QueuedTopic, error) {
+	var topic QueuedTopic
+	var createdAt, usedAt string
+	err := row.Scan(&topic.ID, &topic.Channel, &topic.Topic, &topic.Source, &topic.Link, &topic.Score, &topic.Status, &topic.RunID, &createdAt, &usedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения темы: %w", err)
+	}
+	topic.CreatedAt, topic.UsedAt = parseTime(createdAt), parseTime(usedAt)
+	return &topic, nil
+}
@@ -0,0 +1,53 @@
+// internal/thumbnail/sharpness.go
+package thumbnail
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg" // Регистрируем декодеры для image.Decode
+	_ "image/png"
+	"os"
+)
+
+// sharpnessScore оценивает резкость изображения как дисперсию лапласиана яркости.
+// Смазанные и переходные кадры дают низкое значение, четкие — высокое.
+func sharpnessScore(path string) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось открыть кадр %s: %w", path, err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось декодировать кадр %s: %w", path, err)
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 3 || height < 3 {
+		return 0, nil
+	}
+
+	gray := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			gray[y*width+x] = 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+		}
+	}
+
+	var sum, sumSq float64
+	count := float64((width - 2) * (height - 2))
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < width-1; x++ {
+			i := y*width + x
+			laplacian := gray[i-width] + gray[i+width] + gray[i-1] + gray[i+1] - 4*gray[i]
+			sum += laplacian
+			sumSq += laplacian * laplacian
+		}
+	}
+
+	mean := sum / count
+	return sumSq/count - mean*mean, nil
+}
@@ -0,0 +1,101 @@
+// internal/thumbnail/thumbnail.go
+package thumbnail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/video"
+	"ai-content-gen/pkg/utils"
+)
+
+const (
+	ModeFrame = "frame" // Лучший кадр из готового видео
+	ModeAI    = "ai"    // Обложка от нейросети изображений
+)
+
+// Generator создает обложку для ролика: выбирает кадр или генерирует изображение
+// и накладывает на него заголовок.
+type Generator struct {
+	Config config.ThumbnailConfig
+	Images *ai.ImageGenerator
+	Editor *video.VideoEditor
+	Logger *utils.Logger
+}
+
+// NewGenerator создает новый экземпляр Generator.
+// images может быть nil, тогда доступен только режим ModeFrame.
+func NewGenerator(cfg config.ThumbnailConfig, images *ai.ImageGenerator, editor *video.VideoEditor, logger *utils.Logger) *Generator {
+	return &Generator{
+		Config: cfg,
+		Images: images,
+		Editor: editor,
+		Logger: logger,
+	}
+}
+
+// Generate создает обложку и сохраняет ее в outputPath.
+// videoPath: готовое видео, из которого извлекается кадр.
+// imagePrompt: промпт для нейросети изображений (используется в режиме ModeAI).
+// title: текст, накладываемый на обложку.
+// Если генерация изображения не удалась, используется лучший кадр из видео.
+func (g *Generator) Generate(videoPath, imagePrompt, title, outputPath string) (string, error) {
+	workDir := filepath.Join(filepath.Dir(outputPath), "thumbnail_work")
+	defer os.RemoveAll(workDir)
+
+	var sourcePath string
+	if g.Config.Mode == ModeAI && g.Images != nil {
+		path, err := g.Images.GenerateImage(imagePrompt, filepath.Join(workDir, "cover_source.png"))
+		if err != nil {
+			g.Logger.Warn("Не удалось сгенерировать обложку нейросетью, используем кадр из видео: %v", err)
+		} else {
+			sourcePath = path
+		}
+	}
+
+	if sourcePath == "" {
+		path, err := g.bestFrame(videoPath, workDir)
+		if err != nil {
+			return "", err
+		}
+		sourcePath = path
+	}
+
+	return g.Editor.RenderCover(sourcePath, outputPath, title, video.CoverStyle{
+		Width:     g.Config.Width,
+		Height:    g.Config.Height,
+		FontFile:  g.Config.FontFile,
+		FontSize:  g.Config.FontSize,
+		FontColor: g.Config.FontColor,
+	})
+}
+
+// bestFrame извлекает кадры на сменах сцен и выбирает самый резкий из них.
+func (g *Generator) bestFrame(videoPath, workDir string) (string, error) {
+	frames, err := g.Editor.ExtractCandidateFrames(videoPath, workDir, g.Config.SceneThreshold, g.Config.MaxCandidates)
+	if err != nil {
+		return "", fmt.Errorf("ошибка извлечения кадров для обложки: %w", err)
+	}
+
+	best, bestScore := "", -1.0
+	for _, frame := range frames {
+		score, err := sharpnessScore(frame)
+		if err != nil {
+			g.Logger.Warn("Не удалось оценить кадр %s: %v", frame, err)
+			continue
+		}
+		g.Logger.Info("Резкость кадра %s: %.1f", filepath.Base(frame), score)
+		if score > bestScore {
+			best, bestScore = frame, score
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("не найдено ни одного пригодного кадра для обложки")
+	}
+	g.Logger.Info("Для обложки выбран кадр %s", best)
+	return best, nil
+}
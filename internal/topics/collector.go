@@ -0,0 +1,136 @@
+// internal/topics/collector.go
+package topics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/config"
+	"ai-content-gen/internal/store"
+	"ai-content-gen/pkg/utils"
+)
+
+const defaultMaxQueued = 5
+
+// Collector собирает кандидатов из источников канала, оценивает их текстовой моделью
+// и ставит лучших в очередь тем.
+type Collector struct {
+	TextGen *ai.TextGenerator
+	Store   *store.Store
+	Client  *http.Client
+	Logger  *utils.Logger
+}
+
+// Result — итог сбора тем для канала.
+type Result struct {
+	Fetched int                 // Сколько кандидатов получено из всех источников
+	Scored  int                 // Сколько новых кандидатов оценено
+	Queued  []store.QueuedTopic // Что поставлено в очередь
+}
+
+// NewCollector создает новый экземпляр Collector.
+func NewCollector(textGen *ai.TextGenerator, history *store.Store, logger *utils.Logger) *Collector {
+	return &Collector{
+		TextGen: textGen,
+		Store:   history,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+		Logger:  logger,
+	}
+}
+
+// Collect собирает темы для канала. Ошибка одного источника не останавливает сбор с остальных.
+func (c *Collector) Collect(channel *config.ChannelConfig) (*Result, error) {
+	cfg := channel.Topics
+	if len(cfg.Sources) == 0 {
+		return nil, fmt.Errorf("у канала %s не настроены источники тем", channel.Name)
+	}
+
+	result := &Result{}
+	var candidates []Candidate
+	seen := make(map[string]bool)
+	for _, sourceCfg := range cfg.Sources {
+		source, err := NewSource(sourceCfg, c.Client)
+		if err != nil {
+			return nil, err
+		}
+		fetched, err := source.Fetch()
+		if err != nil {
+			c.Logger.Warn("Источник тем %s недоступен: %v", source.Name(), err)
+			continue
+		}
+		c.Logger.Info("Источник тем %s: %d кандидатов", source.Name(), len(fetched))
+		result.Fetched += len(fetched)
+
+		for _, candidate := range fetched {
+			if cfg.MaxAge > 0 && !candidate.PublishedAt.IsZero() && time.Since(candidate.PublishedAt) > cfg.MaxAge {
+				continue
+			}
+			key := strings.ToLower(candidate.Title)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			// Темы, которые уже были в очереди канала, повторно не оцениваем
+			known, err := c.Store.TopicExists(channel.Name, candidate.Title)
+			if err != nil {
+				return nil, err
+			}
+			if !known {
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		c.Logger.Info("Новых тем для канала %s не найдено", channel.Name)
+		return result, nil
+	}
+
+	titles := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		titles[i] = candidate.Title
+		if candidate.Summary != "" {
+			titles[i] += " — " + truncate(candidate.Summary, 150)
+		}
+	}
+	scores, err := c.TextGen.ScoreTopics(channel.Name, titles)
+	if err != nil {
+		return nil, err
+	}
+	result.Scored = len(candidates)
+
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	maxQueued := cfg.MaxQueued
+	if maxQueued <= 0 {
+		maxQueued = defaultMaxQueued
+	}
+	for _, i := range order {
+		if len(result.Queued) >= maxQueued || scores[i] < cfg.MinScore {
+			break
+		}
+		topic := store.QueuedTopic{
+			Channel: channel.Name,
+			Topic:   candidates[i].Title,
+			Source:  candidates[i].Source,
+			Link:    candidates[i].Link,
+			Score:   scores[i],
+		}
+		inserted, err := c.Store.EnqueueTopic(topic)
+		if err != nil {
+			return nil, err
+		}
+		if inserted {
+			c.Logger.Info("В очередь канала %s: %s (оценка %.1f, %s)", channel.Name, topic.Topic, topic.Score, topic.Source)
+			result.Queued = append(result.Queued, topic)
+		}
+	}
+	return result, nil
+}
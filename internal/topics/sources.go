@@ -0,0 +1,215 @@
+// internal/topics/sources.go
+package topics
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"ai-content-gen/internal/config"
+)
+
+const defaultSourceLimit = 20
+
+// Candidate — тема-кандидат из источника.
+type Candidate struct {
+	Title       string
+	Summary     string // Краткое описание записи ленты или заметка из CSV
+	Link        string
+	Source      string // Имя источника из конфигурации
+	PublishedAt time.Time
+}
+
+// Source возвращает свежих кандидатов в темы.
+type Source interface {
+	Name() string
+	Fetch() ([]Candidate, error)
+}
+
+// NewSource создает источник по его конфигурации.
+func NewSource(cfg config.TopicSourceConfig, client *http.Client) (Source, error) {
+	if cfg.Limit <= 0 {
+		cfg.Limit = defaultSourceLimit
+	}
+	if cfg.Name == "" {
+		cfg.Name = cfg.Type
+	}
+	switch cfg.Type {
+	case config.TopicSourceFeed:
+		return &FeedSource{Config: cfg, Client: client}, nil
+	case config.TopicSourceCSV:
+		return &CSVSource{Config: cfg}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный тип источника тем: %q", cfg.Type)
+	}
+}
+
+// FeedSource читает записи RSS 2.0 или Atom ленты.
+type FeedSource struct {
+	Config config.TopicSourceConfig
+	Client *http.Client
+}
+
+// Name возвращает имя источника.
+func (s *FeedSource) Name() string { return s.Config.Name }
+
+// feedDocument покрывает оба формата: у RSS записи лежат в channel/item, у Atom — в entry.
+type feedDocument struct {
+	Items []struct {
+		Title       string `xml:"title"`
+		Description string `xml:"description"`
+		Link        string `xml:"link"`
+		PubDate     string `xml:"pubDate"`
+	} `xml:"channel>item"`
+	Entries []struct {
+		Title   string `xml:"title"`
+		Summary string `xml:"summary"`
+		Content string `xml:"content"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// Fetch загружает ленту и возвращает не более Limit записей.
+func (s *FeedSource) Fetch() ([]Candidate, error) {
+	req, err := http.NewRequest("GET", s.Config.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса к ленте %s: %w", s.Config.Name, err)
+	}
+	req.Header.Set("User-Agent", "ai-content-gen")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки ленты %s: %w", s.Config.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("лента %s вернула статус %d", s.Config.Name, resp.StatusCode)
+	}
+	return ParseFeed(io.LimitReader(resp.Body, 10<<20), s.Config.Name, s.Config.Limit)
+}
+
+// ParseFeed разбирает RSS или Atom и возвращает не более limit записей с непустым заголовком.
+func ParseFeed(r io.Reader, sourceName string, limit int) ([]Candidate, error) {
+	var doc feedDocument
+	// Поддерживаются ленты в UTF-8; для других кодировок декодер вернет ошибку
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ошибка разбора ленты %s: %w", sourceName, err)
+	}
+
+	var candidates []Candidate
+	add := func(candidate Candidate) {
+		candidate.Title = cleanText(candidate.Title)
+		candidate.Summary = truncate(cleanText(candidate.Summary), 300)
+		if candidate.Title != "" && len(candidates) < limit {
+			candidate.Source = sourceName
+			candidates = append(candidates, candidate)
+		}
+	}
+	for _, item := range doc.Items {
+		add(Candidate{Title: item.Title, Summary: item.Description, Link: strings.TrimSpace(item.Link), PublishedAt: parseFeedTime(item.PubDate)})
+	}
+	for _, entry := range doc.Entries {
+		candidate := Candidate{Title: entry.Title, Summary: entry.Summary, PublishedAt: parseFeedTime(entry.Published)}
+		if candidate.Summary == "" {
+			candidate.Summary = entry.Content
+		}
+		if candidate.PublishedAt.IsZero() {
+			candidate.PublishedAt = parseFeedTime(entry.Updated)
+		}
+		for _, link := range entry.Links {
+			if link.Rel == "" || link.Rel == "alternate" {
+				candidate.Link = link.Href
+				break
+			}
+		}
+		add(candidate)
+	}
+	return candidates, nil
+}
+
+// CSVSource читает темы из локального CSV-файла с колонками topic[,note] и строкой заголовка.
+type CSVSource struct {
+	Config config.TopicSourceConfig
+}
+
+// Name возвращает имя источника.
+func (s *CSVSource) Name() string { return s.Config.Name }
+
+// Fetch читает не более Limit тем из файла.
+func (s *CSVSource) Fetch() ([]Candidate, error) {
+	file, err := os.Open(s.Config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия списка тем %s: %w", s.Config.Path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var candidates []Candidate
+	for line := 1; len(candidates) < s.Config.Limit; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения списка тем %s: %w", s.Config.Path, err)
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "topic") {
+			continue
+		}
+		candidate := Candidate{Title: strings.TrimSpace(record[0]), Source: s.Config.Name}
+		if len(record) > 1 {
+			candidate.Summary = strings.TrimSpace(record[1])
+		}
+		if candidate.Title != "" {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// cleanText убирает HTML-разметку и лишние пробелы из текста записи.
+func cleanText(text string) string {
+	text = html.UnescapeString(htmlTagRegex.ReplaceAllString(text, " "))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// truncate обрезает строку до n символов.
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+var feedTimeLayouts = []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2006-01-02"}
+
+// parseFeedTime разбирает дату записи RSS (RFC 1123) или Atom (RFC 3339). Нераспознанная дата — нулевое время.
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
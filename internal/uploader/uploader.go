@@ -3,6 +3,7 @@ package uploader
 
 import (
 	"fmt"
+	"time"
 
 	"ai-content-gen/pkg/utils"
 )
@@ -15,23 +16,42 @@ const (
 	PlatformTikTok  PlatformType = "tiktok"
 )
 
+// Platforms перечисляет поддерживаемые платформы.
+var Platforms = []PlatformType{PlatformYouTube, PlatformTikTok}
+
 // VideoUploader определяет интерфейс для загрузки видео на конкретную платформу.
 type VideoUploader interface {
 	Upload(videoPath, title, description, tags string) (string, error)
 }
 
+// ThumbnailUploader реализуется загрузчиками платформ, поддерживающих собственную обложку.
+type ThumbnailUploader interface {
+	SetThumbnail(videoURL, thumbnailPath string) error
+}
+
+// ScheduledUploader реализуется загрузчиками платформ, которые сами выпускают ролик в заданное время.
+// Для остальных платформ отложенная публикация держится в локальной очереди.
+type ScheduledUploader interface {
+	UploadScheduled(videoPath, title, description, tags string, publishAt time.Time) (string, error)
+}
+
+// Renditions сопоставляет имя варианта кодирования ролика и путь к файлу.
+type Renditions map[string]string
+
 // MultiPlatformUploader управляет загрузкой на различные платформы.
 type MultiPlatformUploader struct {
-	platforms map[PlatformType]VideoUploader
-	Logger    *utils.Logger
+	platforms  map[PlatformType]VideoUploader
+	renditions map[PlatformType]string // Предпочитаемый вариант ролика для каждой платформы
+	Logger     *utils.Logger
 }
 
 // NewMultiPlatformUploader создает новый экземпляр MultiPlatformUploader.
 // Принимает API-ключи напрямую, так как они будут загружены в main из .env.
 func NewMultiPlatformUploader(youtubeAPIKey, tiktokAPIKey string, logger *utils.Logger) *MultiPlatformUploader {
 	m := &MultiPlatformUploader{
-		platforms: make(map[PlatformType]VideoUploader),
-		Logger:    logger,
+		platforms:  make(map[PlatformType]VideoUploader),
+		renditions: make(map[PlatformType]string),
+		Logger:     logger,
 	}
 
 	// Инициализируем загрузчики для каждой платформы
@@ -41,6 +61,26 @@ func NewMultiPlatformUploader(youtubeAPIKey, tiktokAPIKey string, logger *utils.
 	return m
 }
 
+// SetPreferredRendition задает вариант ролика, который загружается на платформу.
+func (m *MultiPlatformUploader) SetPreferredRendition(platform PlatformType, rendition string) {
+	m.renditions[platform] = rendition
+}
+
+// PickRendition возвращает путь к варианту ролика, выбранному для платформы,
+// или fallbackPath, если вариант не задан или не был создан.
+func (m *MultiPlatformUploader) PickRendition(platform PlatformType, renditions Renditions, fallbackPath string) string {
+	name, ok := m.renditions[platform]
+	if !ok || name == "" {
+		return fallbackPath
+	}
+	path, ok := renditions[name]
+	if !ok {
+		m.Logger.Warn("Вариант '%s' для платформы %s не создан, используем основной файл", name, platform)
+		return fallbackPath
+	}
+	return path
+}
+
 // Upload загружает видео на указанную платформу.
 func (m *MultiPlatformUploader) Upload(platform PlatformType, videoPath, title, description, tags string) (string, error) {
 	uploader, ok := m.platforms[platform]
@@ -57,3 +97,53 @@ func (m *MultiPlatformUploader) Upload(platform PlatformType, videoPath, title,
 	m.Logger.Info("Видео успешно загружено на %s. URL: %s", platform, url)
 	return url, nil
 }
+
+// SupportsScheduling сообщает, умеет ли платформа сама выпустить ролик в заданное время.
+func (m *MultiPlatformUploader) SupportsScheduling(platform PlatformType) bool {
+	_, ok := m.platforms[platform].(ScheduledUploader)
+	return ok
+}
+
+// UploadScheduled загружает видео с отложенным выходом в publishAt.
+// Возвращает ошибку, если платформа не поддерживает отложенную публикацию (см. SupportsScheduling).
+func (m *MultiPlatformUploader) UploadScheduled(platform PlatformType, videoPath, title, description, tags string, publishAt time.Time) (string, error) {
+	uploader, ok := m.platforms[platform]
+	if !ok {
+		return "", fmt.Errorf("загрузчик для платформы %s не найден", platform)
+	}
+	scheduled, ok := uploader.(ScheduledUploader)
+	if !ok {
+		return "", fmt.Errorf("платформа %s не поддерживает отложенную публикацию", platform)
+	}
+
+	m.Logger.Info("Запуск загрузки видео '%s' на платформу %s с публикацией в %s", videoPath, platform, publishAt.Format(time.DateTime))
+	url, err := scheduled.UploadScheduled(videoPath, title, description, tags, publishAt)
+	if err != nil {
+		m.Logger.Error("Ошибка загрузки на %s: %v", platform, err)
+		return "", err
+	}
+	m.Logger.Info("Видео загружено на %s и выйдет %s. URL: %s", platform, publishAt.Format(time.DateTime), url)
+	return url, nil
+}
+
+// SetThumbnail устанавливает обложку для уже загруженного видео.
+// Для платформ без поддержки собственных обложек только пишет предупреждение.
+func (m *MultiPlatformUploader) SetThumbnail(platform PlatformType, videoURL, thumbnailPath string) error {
+	uploader, ok := m.platforms[platform]
+	if !ok {
+		return fmt.Errorf("загрузчик для платформы %s не найден", platform)
+	}
+
+	thumbUploader, ok := uploader.(ThumbnailUploader)
+	if !ok {
+		m.Logger.Warn("Платформа %s не поддерживает собственные обложки, пропускаем", platform)
+		return nil
+	}
+
+	m.Logger.Info("Установка обложки '%s' для видео %s на платформе: %s", thumbnailPath, videoURL, platform)
+	if err := thumbUploader.SetThumbnail(videoURL, thumbnailPath); err != nil {
+		m.Logger.Error("Ошибка установки обложки на %s: %v", platform, err)
+		return err
+	}
+	return nil
+}
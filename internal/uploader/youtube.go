@@ -3,7 +3,9 @@ package uploader
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	"ai-content-gen/pkg/utils"
 )
@@ -39,3 +41,43 @@ func (u *YouTubeUploader) Upload(videoPath, title, description, tags string) (st
 	return fmt.Sprintf("http://youtube.com/watch?v=%s", dummyVideoID), nil
 	// --- КОНЕЦ ЗАГЛУШКИ ---
 }
+
+// UploadScheduled загружает видеофайл на YouTube с отложенной публикацией.
+// В реальной реализации видео загружается через videos.insert со status.privacyStatus=private
+// и status.publishAt в формате RFC 3339: YouTube сам сделает его публичным в указанное время.
+func (u *YouTubeUploader) UploadScheduled(videoPath, title, description, tags string, publishAt time.Time) (string, error) {
+	u.Logger.Info("Начало загрузки видео на YouTube с публикацией в %s: %s", publishAt.Format(time.RFC3339), videoPath)
+	u.Logger.Info("Название: %s, Описание: %s, Теги: %s", title, description, tags)
+
+	if u.APIKey == "" {
+		u.Logger.Warn("YouTube API Key не предоставлен. Загрузка на YouTube невозможна.")
+		return "", fmt.Errorf("YouTube API Key не предоставлен")
+	}
+	if !publishAt.After(time.Now()) {
+		return "", fmt.Errorf("время публикации %s уже прошло", publishAt.Format(time.RFC3339))
+	}
+
+	// --- ЗАГЛУШКА ДЛЯ YouTube ---
+	u.Logger.Warn("Внимание: Отложенная загрузка на YouTube - это заглушка. Передайте status.privacyStatus=private и status.publishAt=%s в videos.insert.", publishAt.UTC().Format(time.RFC3339))
+	dummyVideoID := fmt.Sprintf("dummy_youtube_id_%s", filepath.Base(videoPath))
+	return fmt.Sprintf("http://youtube.com/watch?v=%s", dummyVideoID), nil
+	// --- КОНЕЦ ЗАГЛУШКИ ---
+}
+
+// SetThumbnail устанавливает собственную обложку для загруженного видео на YouTube.
+// В реальной реализации здесь будет вызов thumbnails.set из YouTube Data API.
+func (u *YouTubeUploader) SetThumbnail(videoURL, thumbnailPath string) error {
+	u.Logger.Info("Установка обложки на YouTube для %s: %s", videoURL, thumbnailPath)
+
+	if u.APIKey == "" {
+		return fmt.Errorf("YouTube API Key не предоставлен")
+	}
+	if _, err := os.Stat(thumbnailPath); err != nil {
+		return fmt.Errorf("файл обложки недоступен: %w", err)
+	}
+
+	// --- ЗАГЛУШКА ДЛЯ YouTube ---
+	u.Logger.Warn("Внимание: Установка обложки на YouTube - это заглушка. Реализуйте вызов thumbnails.set здесь.")
+	return nil
+	// --- КОНЕЦ ЗАГЛУШКИ ---
+}
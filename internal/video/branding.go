@@ -0,0 +1,195 @@
+// internal/video/branding.go
+package video
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LogoOptions описывает водяной знак канала.
+type LogoOptions struct {
+	Path     string
+	Position string  // top-left, top-right, bottom-left, bottom-right
+	Opacity  float64 // 0..1
+	Scale    float64 // Ширина логотипа как доля ширины кадра
+	Margin   int     // Отступ от края кадра в пикселях
+}
+
+// ApplyWatermark накладывает логотип канала поверх всего видео.
+// width: ширина кадра в пикселях, от нее считается размер логотипа.
+func (ve *VideoEditor) ApplyWatermark(inputPath, outputPath string, width int, logo LogoOptions) (string, error) {
+	if logo.Path == "" {
+		return inputPath, nil
+	}
+	if _, err := os.Stat(logo.Path); err != nil {
+		ve.Logger.Warn("Логотип %s недоступен, водяной знак пропущен: %v", logo.Path, err)
+		return inputPath, nil
+	}
+	ve.Logger.Info("Наложение водяного знака %s на видео: %s", logo.Path, inputPath)
+
+	scale, opacity := logo.Scale, logo.Opacity
+	if scale <= 0 {
+		scale = 0.15
+	}
+	if opacity <= 0 || opacity > 1 {
+		opacity = 1
+	}
+	m := logo.Margin
+
+	var x, y string
+	switch logo.Position {
+	case "top-left":
+		x, y = fmt.Sprintf("%d", m), fmt.Sprintf("%d", m)
+	case "bottom-left":
+		x, y = fmt.Sprintf("%d", m), fmt.Sprintf("H-h-%d", m)
+	case "bottom-right":
+		x, y = fmt.Sprintf("W-w-%d", m), fmt.Sprintf("H-h-%d", m)
+	default: // top-right
+		x, y = fmt.Sprintf("W-w-%d", m), fmt.Sprintf("%d", m)
+	}
+
+	filter := fmt.Sprintf("[1:v]scale=%d:-1,format=rgba,colorchannelmixer=aa=%.2f[logo];[0:v][logo]overlay=%s:%s[v]",
+		int(float64(width)*scale), opacity, x, y)
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("не удалось создать директорию для видео с водяным знаком: %w", err)
+	}
+	cmdArgs := []string{
+		"-y", "-i", inputPath, "-i", logo.Path,
+		"-filter_complex", filter,
+		"-map", "[v]", "-map", "0:a?",
+		"-c:v", "libx264", "-preset", "fast", "-crf", "20",
+		"-c:a", "copy",
+		outputPath,
+	}
+	if err := ve.runFFmpeg("водяной знак", ve.durationOrZero(inputPath), cmdArgs...); err != nil {
+		return "", err
+	}
+
+	ve.Logger.Info("Водяной знак наложен: %s", outputPath)
+	return outputPath, nil
+}
+
+// RenderEndCard создает финальную карточку канала длительностью duration секунд.
+// Фоном служит imagePath, а если он не задан — сплошной черный кадр; text накладывается по центру.
+func (ve *VideoEditor) RenderEndCard(imagePath, text, outputPath string, style CoverStyle, duration float64, fps int) (string, error) {
+	ve.Logger.Info("Создание финальной карточки: %s", outputPath)
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("не удалось создать директорию для финальной карточки: %w", err)
+	}
+
+	var cmdArgs []string
+	if imagePath != "" {
+		cmdArgs = []string{"-y", "-loop", "1", "-i", imagePath}
+	} else {
+		cmdArgs = []string{"-y", "-f", "lavfi", "-i", fmt.Sprintf("color=c=black:s=%dx%d:r=%d", style.Width, style.Height, fps)}
+	}
+
+	filter := fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,setsar=1,fps=%d,format=yuv420p",
+		style.Width, style.Height, style.Width, style.Height, fps)
+	if strings.TrimSpace(text) != "" {
+		textFile := outputPath + ".txt"
+		if err := os.WriteFile(textFile, []byte(text), 0o644); err != nil {
+			return "", fmt.Errorf("не удалось записать текст финальной карточки: %w", err)
+		}
+		defer os.Remove(textFile)
+
+		filter += ",drawtext="
+		if font := ve.ResolveFont(style.FontFile); font != "" {
+			filter += fmt.Sprintf("fontfile=%s:", escapeFilterValue(font))
+		}
+		filter += fmt.Sprintf("textfile=%s:fontsize=%d:fontcolor=%s:borderw=4:bordercolor=black:x=(w-text_w)/2:y=(h-text_h)/2",
+			escapeFilterValue(textFile), style.FontSize, style.FontColor)
+	}
+
+	cmdArgs = append(cmdArgs,
+		"-t", fmt.Sprintf("%.3f", duration),
+		"-vf", filter,
+		"-c:v", "libx264", "-preset", "fast", "-crf", "20",
+		outputPath,
+	)
+	if err := ve.runFFmpeg("финальная карточка", duration, cmdArgs...); err != nil {
+		return "", err
+	}
+	return outputPath, nil
+}
+
+// ConcatenateNormalized склеивает видео с разными параметрами (например, заставки канала
+// и сгенерированный ролик), приводя каждое к общему разрешению и частоте кадров.
+// В отличие от ConcatenateVideos, видео перекодируется. Если хотя бы у одного входа есть звук,
+// для остальных подставляется тишина, чтобы дорожки совпадали.
+func (ve *VideoEditor) ConcatenateNormalized(inputPaths []string, outputPath string, width, height, fps int) (string, error) {
+	ve.Logger.Info("Склейка с нормализацией (%dx%d, %d fps): %v в %s", width, height, fps, inputPaths, outputPath)
+
+	if len(inputPaths) == 0 {
+		return "", fmt.Errorf("нет входных видеофайлов для склейки")
+	}
+
+	withAudio := false
+	audio := make([]bool, len(inputPaths))
+	var total float64
+	for i, path := range inputPaths {
+		audio[i] = ve.hasAudio(path)
+		withAudio = withAudio || audio[i]
+		total += ve.durationOrZero(path)
+	}
+
+	var cmdArgs []string
+	for _, path := range inputPaths {
+		cmdArgs = append(cmdArgs, "-i", path)
+	}
+
+	var filter strings.Builder
+	var concatInputs strings.Builder
+	silentIndex := len(inputPaths)
+	for i, path := range inputPaths {
+		fmt.Fprintf(&filter, "[%d:v]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=%d,format=yuv420p[v%d];",
+			i, width, height, width, height, fps, i)
+		fmt.Fprintf(&concatInputs, "[v%d]", i)
+
+		if !withAudio {
+			continue
+		}
+		if audio[i] {
+			fmt.Fprintf(&filter, "[%d:a]aresample=44100,aformat=channel_layouts=stereo[a%d];", i, i)
+		} else {
+			duration, err := ve.ProbeDuration(path)
+			if err != nil {
+				return "", err
+			}
+			cmdArgs = append(cmdArgs, "-f", "lavfi", "-t", fmt.Sprintf("%.3f", duration), "-i", "anullsrc=r=44100:cl=stereo")
+			fmt.Fprintf(&filter, "[%d:a]anull[a%d];", silentIndex, i)
+			silentIndex++
+		}
+		fmt.Fprintf(&concatInputs, "[a%d]", i)
+	}
+
+	audioStreams := 0
+	if withAudio {
+		audioStreams = 1
+	}
+	fmt.Fprintf(&filter, "%sconcat=n=%d:v=1:a=%d[v]", concatInputs.String(), len(inputPaths), audioStreams)
+	if withAudio {
+		filter.WriteString("[a]")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("не удалось создать выходную директорию для %s: %w", outputPath, err)
+	}
+
+	cmdArgs = append(cmdArgs, "-y", "-filter_complex", filter.String(), "-map", "[v]")
+	if withAudio {
+		cmdArgs = append(cmdArgs, "-map", "[a]", "-c:a", "aac", "-b:a", "128k")
+	}
+	cmdArgs = append(cmdArgs, "-c:v", "libx264", "-preset", "fast", "-crf", "20", outputPath)
+
+	if err := ve.runFFmpeg("склейка с нормализацией", total, cmdArgs...); err != nil {
+		return "", err
+	}
+
+	ve.Logger.Info("Видео успешно склеено с нормализацией в: %s", outputPath)
+	return outputPath, nil
+}
@@ -0,0 +1,68 @@
+// internal/video/duration.go
+package video
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FitDuration гарантирует, что видео не длиннее maxDuration секунд.
+// Если превышение не больше maxSpeedup раз, видео равномерно ускоряется,
+// иначе — обрезается по maxDuration. Если видео укладывается в лимит, возвращается inputPath без изменений.
+func (ve *VideoEditor) FitDuration(inputPath, outputPath string, maxDuration, maxSpeedup float64) (string, error) {
+	if maxDuration <= 0 {
+		return inputPath, nil
+	}
+
+	duration, err := ve.ProbeDuration(inputPath)
+	if err != nil {
+		return "", err
+	}
+	if duration <= maxDuration {
+		ve.Logger.Info("Длительность видео %.2f с укладывается в лимит %.2f с", duration, maxDuration)
+		return inputPath, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("не удалось создать выходную директорию для %s: %w", outputPath, err)
+	}
+
+	ratio := duration / maxDuration
+	var cmdArgs []string
+	if ratio <= maxSpeedup {
+		ve.Logger.Info("Видео длится %.2f с при лимите %.2f с, ускоряем в %.3f раза", duration, maxDuration, ratio)
+		cmdArgs = []string{"-y", "-i", inputPath, "-filter:v", fmt.Sprintf("setpts=PTS/%.4f", ratio)}
+		if ve.hasAudio(inputPath) {
+			cmdArgs = append(cmdArgs, "-filter:a", atempoChain(ratio))
+		}
+	} else {
+		ve.Logger.Warn("Видео длится %.2f с при лимите %.2f с, ускорение в %.3f раза превышает допустимое, обрезаем", duration, maxDuration, ratio)
+		cmdArgs = []string{"-y", "-i", inputPath, "-c", "copy"}
+	}
+	// -t как параметр выхода дополнительно страхует от погрешности округления при ускорении
+	cmdArgs = append(cmdArgs, "-t", fmt.Sprintf("%.3f", maxDuration), outputPath)
+
+	if err := ve.runFFmpeg("подгонка длительности", maxDuration, cmdArgs...); err != nil {
+		return "", err
+	}
+
+	ve.Logger.Info("Видео подогнано под лимит длительности: %s", outputPath)
+	return outputPath, nil
+}
+
+// maxAtempo — наибольший коэффициент одного фильтра atempo.
+const maxAtempo = 2.0
+
+// atempoChain возвращает фильтр ускорения звука в ratio раз. Один atempo принимает коэффициент
+// не больше 2, поэтому большее ускорение собирается из цепочки фильтров.
+func atempoChain(ratio float64) string {
+	var filters []string
+	for ratio > maxAtempo {
+		filters = append(filters, fmt.Sprintf("atempo=%.1f", maxAtempo))
+		ratio /= maxAtempo
+	}
+	filters = append(filters, fmt.Sprintf("atempo=%.4f", ratio))
+	return strings.Join(filters, ",")
+}
@@ -0,0 +1,41 @@
+// internal/video/duration_test.go
+package video
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAtempoChain(t *testing.T) {
+	tests := []struct {
+		ratio float64
+		want  string
+	}{
+		{1.15, "atempo=1.1500"},
+		{2, "atempo=2.0000"},
+		{3, "atempo=2.0,atempo=1.5000"},
+		{4, "atempo=2.0,atempo=2.0000"},
+		{5, "atempo=2.0,atempo=2.0,atempo=1.2500"},
+	}
+	for _, tt := range tests {
+		got := atempoChain(tt.ratio)
+		if got != tt.want {
+			t.Errorf("atempoChain(%v) = %q, ожидалось %q", tt.ratio, got, tt.want)
+		}
+
+		// Каждый фильтр должен быть в пределах, которые принимает atempo, а вместе — давать нужное ускорение
+		product := 1.0
+		for _, filter := range strings.Split(got, ",") {
+			factor, err := strconv.ParseFloat(strings.TrimPrefix(filter, "atempo="), 64)
+			if err != nil || factor < 0.5 || factor > maxAtempo {
+				t.Errorf("atempoChain(%v): недопустимый фильтр %q", tt.ratio, filter)
+			}
+			product *= factor
+		}
+		if math.Abs(product-tt.ratio) > 1e-3 {
+			t.Errorf("atempoChain(%v) ускоряет в %v раз", tt.ratio, product)
+		}
+	}
+}
@@ -2,10 +2,8 @@
 package video
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -14,7 +12,9 @@ import (
 
 // VideoEditor отвечает за обработку и склейку видео.
 type VideoEditor struct {
-	Logger *utils.Logger
+	Logger     *utils.Logger
+	OnProgress ProgressFunc // Необязательный обработчик прогресса FFmpeg
+	OnCommand  CommandFunc  // Необязательный обработчик завершенных команд FFmpeg
 }
 
 // NewVideoEditor создает новый экземпляр VideoEditor.
@@ -35,8 +35,12 @@ func (ve *VideoEditor) ConcatenateVideos(inputPaths []string, outputPath string,
 		return "", fmt.Errorf("нет входных видеофайлов для склейки")
 	}
 
-	// Создаем временный файл-список для FFmpeg
-	listFilePath := "temp_videos/concat_list.txt"
+	// Создаем временный файл-список для FFmpeg рядом с выходным файлом,
+	// чтобы параллельные запуски в разных рабочих директориях не мешали друг другу
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("не удалось создать выходную директорию %s: %w", filepath.Dir(outputPath), err)
+	}
+	listFilePath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "_concat_list.txt"
 	listFile, err := os.Create(listFilePath)
 	if err != nil {
 		return "", fmt.Errorf("не удалось создать список файлов для FFmpeg: %w", err)
@@ -45,19 +49,18 @@ func (ve *VideoEditor) ConcatenateVideos(inputPaths []string, outputPath string,
 	defer os.Remove(listFilePath) // Удаляем временный файл после использования
 
 	for _, path := range inputPaths {
-		_, err := listFile.WriteString(fmt.Sprintf("file '%s'\n", filepath.ToSlash(path))) // FFmpeg предпочитает /
+		// Относительные пути FFmpeg считает от файла-списка, поэтому записываем абсолютные
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return "", fmt.Errorf("не удалось определить абсолютный путь %s: %w", path, err)
+		}
+		_, err = listFile.WriteString(fmt.Sprintf("file '%s'\n", strings.ReplaceAll(filepath.ToSlash(absPath), "'", `'\''`))) // FFmpeg предпочитает /
 		if err != nil {
 			return "", fmt.Errorf("не удалось записать в список файлов для FFmpeg: %w", err)
 		}
 	}
 	listFile.Close() // Закрыть, чтобы FFmpeg мог его прочитать
 
-	// Создаем директорию для выходного видео, если ее нет
-	outputDir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil && !os.IsExist(err) {
-		return "", fmt.Errorf("не удалось создать выходную директорию %s: %w", outputDir, err)
-	}
-
 	// Команда FFmpeg для склейки
 	// -f concat: указывает формат входного файла как "concat" (для списка файлов)
 	// -safe 0: разрешает произвольные пути в файле списка (может быть небезопасно, но нужно для некоторых путей)
@@ -68,7 +71,9 @@ func (ve *VideoEditor) ConcatenateVideos(inputPaths []string, outputPath string,
 	// -vf "fps=30,scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920"
 	// (Для Shorts: 1080x1920, 30 FPS, вертикальное видео)
 	// Я добавляю FPS, остальные параметры можно добавить в config.yaml и передавать.
+	// -y: перезаписать результат прошлой попытки, если этап сборки повторяется в той же директории
 	cmdArgs := []string{
+		"-y",
 		"-f", "concat",
 		"-safe", "0",
 		"-i", listFilePath,
@@ -77,22 +82,14 @@ func (ve *VideoEditor) ConcatenateVideos(inputPaths []string, outputPath string,
 		outputPath,
 	}
 
-	ve.Logger.Info("Запуск FFmpeg с командой: ffmpeg %s", strings.Join(cmdArgs, " "))
-	cmd := exec.Command("ffmpeg", cmdArgs...)
-
-	// Захват вывода FFmpeg для отладки
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err = cmd.Run()
-	if err != nil {
-		ve.Logger.Error("Ошибка FFmpeg. Stdout: %s", stdout.String())
-		ve.Logger.Error("Ошибка FFmpeg. Stderr: %s", stderr.String())
-		return "", fmt.Errorf("ошибка выполнения команды FFmpeg: %w", err)
+	var total float64
+	for _, path := range inputPaths {
+		total += ve.durationOrZero(path)
+	}
+	if err := ve.runFFmpeg("склейка", total, cmdArgs...); err != nil {
+		return "", err
 	}
 
-	ve.Logger.Info("FFmpeg успешно завершил склейку. Stdout: %s", stdout.String())
 	ve.Logger.Info("Видео успешно склеено в: %s", outputPath)
 	return outputPath, nil
 }
@@ -0,0 +1,88 @@
+// internal/video/errors.go
+package video
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// FFmpegError описывает неудачный запуск FFmpeg.
+type FFmpegError struct {
+	Task     string   // Название операции
+	Args     []string // Аргументы командной строки
+	ExitCode int      // Код выхода; -1, если процесс не удалось запустить
+	Message  string   // Главная строка ошибки из stderr
+	Stderr   string   // Последние строки stderr для подробной диагностики
+	Err      error
+}
+
+func (e *FFmpegError) Error() string {
+	return fmt.Sprintf("ошибка FFmpeg (%s, код %d): %s", e.Task, e.ExitCode, e.Message)
+}
+
+func (e *FFmpegError) Unwrap() error {
+	return e.Err
+}
+
+// stderrTailLines — сколько последних строк stderr сохраняется в FFmpegError.
+const stderrTailLines = 20
+
+// ffmpegErrorMarkers — фрагменты, по которым строка stderr распознается как описание ошибки.
+var ffmpegErrorMarkers = []string{
+	"error", "invalid", "no such file", "not found", "unknown", "unrecognized",
+	"does not contain", "could not", "unable", "permission denied", "cannot", "failed",
+}
+
+// newFFmpegError собирает FFmpegError из вывода stderr и ошибки завершения процесса.
+func newFFmpegError(task string, args []string, stderr string, err error) *FFmpegError {
+	ffErr := &FFmpegError{
+		Task:     task,
+		Args:     args,
+		ExitCode: -1,
+		Err:      err,
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		ffErr.ExitCode = exitErr.ExitCode()
+	}
+
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > stderrTailLines {
+		ffErr.Stderr = strings.Join(lines[len(lines)-stderrTailLines:], "\n")
+	} else {
+		ffErr.Stderr = strings.Join(lines, "\n")
+	}
+
+	ffErr.Message = extractErrorLine(lines)
+	if ffErr.Message == "" {
+		ffErr.Message = err.Error()
+	}
+	return ffErr
+}
+
+// extractErrorLine выбирает самую информативную строку ошибки: последнюю строку с признаками ошибки,
+// не считая общих итоговых сообщений вроде "Conversion failed!". Если таких нет — последнюю строку.
+func extractErrorLine(lines []string) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		lower := strings.ToLower(lines[i])
+		if strings.HasPrefix(lower, "conversion failed") || strings.HasPrefix(lower, "error while filtering") {
+			continue
+		}
+		for _, marker := range ffmpegErrorMarkers {
+			if strings.Contains(lower, marker) {
+				return lines[i]
+			}
+		}
+	}
+	if len(lines) > 0 {
+		return lines[len(lines)-1]
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+// internal/video/ffmpeg.go
+package video
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Command описывает завершенный запуск FFmpeg для журнала запуска.
+type Command struct {
+	Task      string
+	Args      []string
+	StartedAt time.Time
+	Duration  time.Duration
+	Err       error // nil, если команда завершилась успешно
+}
+
+// CommandFunc получает сведения о каждом завершенном запуске FFmpeg.
+type CommandFunc func(Command)
+
+// runFFmpeg запускает ffmpeg с указанными аргументами, передавая ход выполнения в reportProgress.
+// task: краткое название операции для логов и обработчика прогресса.
+// total: ожидаемая длительность результата в секундах (0 — неизвестна, процент не считается).
+// При ошибке возвращает *FFmpegError с осмысленной строкой из stderr.
+func (ve *VideoEditor) runFFmpeg(task string, total float64, args ...string) (runErr error) {
+	fullArgs := append([]string{"-hide_banner", "-nostats", "-loglevel", "error", "-progress", "pipe:1"}, args...)
+	ve.Logger.Info("Запуск FFmpeg (%s) с командой: ffmpeg %s", task, strings.Join(fullArgs, " "))
+	cmd := exec.Command("ffmpeg", fullArgs...)
+
+	if ve.OnCommand != nil {
+		started := time.Now()
+		defer func() {
+			ve.OnCommand(Command{Task: task, Args: fullArgs, StartedAt: started, Duration: time.Since(started), Err: runErr})
+		}()
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("не удалось подключиться к выводу FFmpeg: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return &FFmpegError{Task: task, Args: fullArgs, ExitCode: -1, Message: err.Error(), Err: err}
+	}
+
+	ve.readProgress(stdout, task, total)
+
+	if err := cmd.Wait(); err != nil {
+		ffErr := newFFmpegError(task, fullArgs, stderr.String(), err)
+		ve.Logger.Error("FFmpeg (%s) завершился с кодом %d: %s", task, ffErr.ExitCode, ffErr.Message)
+		return ffErr
+	}
+	return nil
+}
+
+// ProbeDuration возвращает длительность медиафайла в секундах с помощью ffprobe.
+func (ve *VideoEditor) ProbeDuration(path string) (float64, error) {
+	out, err := exec.Command("ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		path,
+	).Output()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка ffprobe для %s: %w", path, err)
+	}
+
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось разобрать длительность %q для %s: %w", strings.TrimSpace(string(out)), path, err)
+	}
+	return duration, nil
+}
+
+// durationOrZero возвращает длительность файла или 0, если ее не удалось определить.
+// Используется только для оценки прогресса, поэтому ошибки не критичны.
+func (ve *VideoEditor) durationOrZero(path string) float64 {
+	duration, err := ve.ProbeDuration(path)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
+
+// hasAudio проверяет, есть ли в файле аудиодорожка.
+func (ve *VideoEditor) hasAudio(path string) bool {
+	out, err := exec.Command("ffprobe",
+		"-v", "error",
+		"-select_streams", "a",
+		"-show_entries", "stream=index",
+		"-of", "csv=p=0",
+		path,
+	).Output()
+	return err == nil && strings.TrimSpace(string(out)) != ""
+}
+
+// ParseResolution разбирает строку вида "1080x1920" на ширину и высоту.
+func ParseResolution(resolution string) (int, int, error) {
+	parts := strings.SplitN(strings.ToLower(resolution), "x", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("ожидается формат ШИРИНАxВЫСОТА, получено %q", resolution)
+	}
+	width, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("некорректная ширина в %q: %w", resolution, err)
+	}
+	height, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("некорректная высота в %q: %w", resolution, err)
+	}
+	return width, height, nil
+}
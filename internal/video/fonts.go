@@ -0,0 +1,50 @@
+// internal/video/fonts.go
+package video
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// cyrillicFonts — распространенные шрифты с поддержкой кириллицы, проверяемые по порядку.
+var cyrillicFonts = []string{
+	"/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf",
+	"/usr/share/fonts/dejavu/DejaVuSans-Bold.ttf",
+	"/usr/share/fonts/truetype/noto/NotoSans-Bold.ttf",
+	"/usr/share/fonts/noto/NotoSans-Bold.ttf",
+	"/usr/share/fonts/truetype/liberation/LiberationSans-Bold.ttf",
+	"/usr/share/fonts/truetype/roboto/unhinted/RobotoTTF/Roboto-Bold.ttf",
+	"/Library/Fonts/Arial Unicode.ttf",
+	"C:/Windows/Fonts/arialbd.ttf",
+}
+
+// ResolveFont возвращает путь к шрифту для drawtext.
+// Если preferred задан и существует, используется он; иначе ищется шрифт
+// с кириллицей через fontconfig, а затем среди известных путей.
+// Возвращает пустую строку, если ничего не найдено (FFmpeg возьмет шрифт по умолчанию).
+func (ve *VideoEditor) ResolveFont(preferred string) string {
+	if preferred != "" {
+		if _, err := os.Stat(preferred); err == nil {
+			return preferred
+		}
+		ve.Logger.Warn("Шрифт %s не найден, подбираем шрифт с поддержкой кириллицы", preferred)
+	}
+
+	if out, err := exec.Command("fc-match", "-f", "%{file}", "sans:bold:lang=ru").Output(); err == nil {
+		if path := strings.TrimSpace(string(out)); path != "" {
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+
+	for _, path := range cyrillicFonts {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	ve.Logger.Warn("Не найден шрифт с поддержкой кириллицы, текст может отображаться некорректно")
+	return ""
+}
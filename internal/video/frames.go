@@ -0,0 +1,121 @@
+// internal/video/frames.go
+package video
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CoverStyle описывает оформление текста на обложке.
+type CoverStyle struct {
+	Width     int
+	Height    int
+	FontFile  string
+	FontSize  int
+	FontColor string
+}
+
+// ExtractCandidateFrames сохраняет в outputDir кадры, на которых происходит смена сцены
+// (scene score выше threshold), но не более maxFrames. Если смен сцены не найдено,
+// сохраняется один наиболее репрезентативный кадр по фильтру thumbnail.
+// Возвращает пути к сохраненным кадрам.
+func (ve *VideoEditor) ExtractCandidateFrames(videoPath, outputDir string, threshold float64, maxFrames int) ([]string, error) {
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("не удалось создать директорию для кадров %s: %w", outputDir, err)
+	}
+	if maxFrames <= 0 {
+		maxFrames = 1
+	}
+
+	pattern := filepath.Join(outputDir, "candidate_%03d.png")
+	total := ve.durationOrZero(videoPath)
+	err := ve.runFFmpeg("кадры для обложки", total,
+		"-y", "-i", videoPath,
+		"-vf", fmt.Sprintf("select='gt(scene,%.3f)'", threshold),
+		"-vsync", "vfr",
+		"-frames:v", fmt.Sprintf("%d", maxFrames),
+		pattern,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	frames, err := filepath.Glob(filepath.Join(outputDir, "candidate_*.png"))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска извлеченных кадров: %w", err)
+	}
+
+	if len(frames) == 0 {
+		ve.Logger.Warn("Смены сцен в %s не найдены, используем фильтр thumbnail", videoPath)
+		fallback := filepath.Join(outputDir, "candidate_thumbnail.png")
+		if err := ve.runFFmpeg("кадр для обложки", total, "-y", "-i", videoPath, "-vf", "thumbnail", "-frames:v", "1", fallback); err != nil {
+			return nil, err
+		}
+		frames = []string{fallback}
+	}
+
+	sort.Strings(frames)
+	ve.Logger.Info("Извлечено %d кадров-кандидатов для обложки", len(frames))
+	return frames, nil
+}
+
+// ExtractLastFrame сохраняет один из последних кадров видео в outputPath.
+// Используется как первый кадр следующего сегмента для плавного перехода между сценами.
+func (ve *VideoEditor) ExtractLastFrame(videoPath, outputPath string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("не удалось создать директорию для кадра: %w", err)
+	}
+	// -sseof отсчитывает позицию от конца файла; -update 1 перезаписывает кадр, оставляя последний
+	if err := ve.runFFmpeg("последний кадр", 0, "-y", "-sseof", "-0.3", "-i", videoPath, "-update", "1", "-q:v", "2", outputPath); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		return "", fmt.Errorf("FFmpeg не сохранил последний кадр %s: %w", videoPath, err)
+	}
+	return outputPath, nil
+}
+
+// RenderCover масштабирует изображение под размер обложки и накладывает на него текст.
+// Текст передается через файл, чтобы не экранировать спецсимволы для drawtext.
+func (ve *VideoEditor) RenderCover(imagePath, outputPath, text string, style CoverStyle) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("не удалось создать директорию для обложки: %w", err)
+	}
+
+	filter := fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d", style.Width, style.Height, style.Width, style.Height)
+
+	if text != "" {
+		textFile := outputPath + ".txt"
+		if err := os.WriteFile(textFile, []byte(text), 0o644); err != nil {
+			return "", fmt.Errorf("не удалось записать текст обложки: %w", err)
+		}
+		defer os.Remove(textFile)
+
+		filter += ",drawtext="
+		if font := ve.ResolveFont(style.FontFile); font != "" {
+			filter += fmt.Sprintf("fontfile=%s:", escapeFilterValue(font))
+		}
+		filter += fmt.Sprintf("textfile=%s:fontsize=%d:fontcolor=%s"+
+			":borderw=4:bordercolor=black@0.8:box=1:boxcolor=black@0.35:boxborderw=24"+
+			":x=(w-text_w)/2:y=h*0.15",
+			escapeFilterValue(textFile), style.FontSize, style.FontColor)
+	}
+
+	if err := ve.runFFmpeg("обложка", 0, "-y", "-i", imagePath, "-vf", filter, "-frames:v", "1", outputPath); err != nil {
+		return "", err
+	}
+
+	ve.Logger.Info("Обложка сохранена: %s", outputPath)
+	return outputPath, nil
+}
+
+// escapeFilterValue готовит значение для подстановки в опцию фильтра FFmpeg:
+// сначала экранирует его на уровне опции (\, ', :), затем заключает в кавычки на уровне графа фильтров.
+func escapeFilterValue(value string) string {
+	value = filepath.ToSlash(value)
+	value = strings.NewReplacer(`\`, `\\`, `:`, `\:`, `'`, `\'`).Replace(value)
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,184 @@
+// internal/video/overlays.go
+package video
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Положения надписи по вертикали.
+const (
+	PositionTop    = "top"
+	PositionCenter = "center"
+	PositionBottom = "bottom"
+)
+
+// Анимации появления надписи.
+const (
+	AnimationNone = "none"
+	AnimationFade = "fade"
+	AnimationPop  = "pop"
+)
+
+// TextOverlay описывает одну надпись поверх видео.
+type TextOverlay struct {
+	Text      string
+	Start     float64 // Время появления в секундах
+	End       float64 // Время исчезновения в секундах
+	Position  string  // PositionTop, PositionCenter или PositionBottom
+	Animation string  // AnimationNone, AnimationFade или AnimationPop
+	FontSize  int
+}
+
+// OverlayStyle задает общее оформление надписей и безопасные зоны платформы.
+type OverlayStyle struct {
+	FontFile    string
+	FontColor   string
+	BorderColor string
+	SafeTop     float64 // Доля высоты кадра сверху, перекрываемая интерфейсом
+	SafeBottom  float64 // Доля высоты кадра снизу, перекрываемая интерфейсом
+	SafeSide    float64 // Доля ширины кадра по бокам
+}
+
+const (
+	animationSeconds = 0.35 // Длительность появления/исчезновения
+	lineSpacing      = 1.25 // Межстрочный интервал относительно размера шрифта
+	charWidthRatio   = 0.55 // Средняя ширина символа относительно размера шрифта
+)
+
+// RenderOverlays накладывает надписи на видео с помощью фильтра drawtext.
+// Каждая строка надписи рисуется отдельно, чтобы центрироваться по горизонтали;
+// длинный текст переносится по ширине безопасной зоны.
+// width: ширина кадра в пикселях, нужна для расчета переносов.
+func (ve *VideoEditor) RenderOverlays(inputPath, outputPath string, width int, overlays []TextOverlay, style OverlayStyle) (string, error) {
+	if len(overlays) == 0 {
+		return inputPath, nil
+	}
+	ve.Logger.Info("Наложение %d надписей на видео: %s", len(overlays), inputPath)
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("не удалось создать директорию для видео с надписями: %w", err)
+	}
+
+	textDir, err := os.MkdirTemp(filepath.Dir(outputPath), "overlays_")
+	if err != nil {
+		return "", fmt.Errorf("не удалось создать директорию для текстов надписей: %w", err)
+	}
+	defer os.RemoveAll(textDir)
+
+	fontFile := ve.ResolveFont(style.FontFile)
+
+	var filters []string
+	for i, overlay := range overlays {
+		if strings.TrimSpace(overlay.Text) == "" || overlay.End <= overlay.Start {
+			continue
+		}
+
+		maxChars := int(float64(width) * (1 - 2*style.SafeSide) / (float64(overlay.FontSize) * charWidthRatio))
+		lines := wrapText(overlay.Text, maxChars)
+		lineHeight := float64(overlay.FontSize) * lineSpacing
+		blockHeight := lineHeight * float64(len(lines))
+
+		for j, line := range lines {
+			textFile := filepath.Join(textDir, fmt.Sprintf("overlay_%d_%d.txt", i, j))
+			if err := os.WriteFile(textFile, []byte(line), 0o644); err != nil {
+				return "", fmt.Errorf("не удалось записать текст надписи: %w", err)
+			}
+
+			y := fmt.Sprintf("%s+%.1f", blockTop(overlay.Position, blockHeight, style), float64(j)*lineHeight)
+			filters = append(filters, drawTextFilter(textFile, fontFile, y, overlay, style))
+		}
+	}
+
+	if len(filters) == 0 {
+		return inputPath, nil
+	}
+
+	cmdArgs := []string{
+		"-y", "-i", inputPath,
+		"-vf", strings.Join(filters, ","),
+		"-c:v", "libx264", "-preset", "fast", "-crf", "20",
+		"-c:a", "copy",
+		outputPath,
+	}
+	if err := ve.runFFmpeg("надписи", ve.durationOrZero(inputPath), cmdArgs...); err != nil {
+		return "", err
+	}
+
+	ve.Logger.Info("Надписи наложены: %s", outputPath)
+	return outputPath, nil
+}
+
+// blockTop возвращает выражение FFmpeg для верхней границы блока текста с учетом безопасных зон.
+func blockTop(position string, blockHeight float64, style OverlayStyle) string {
+	switch position {
+	case PositionTop:
+		return fmt.Sprintf("h*%.3f", style.SafeTop)
+	case PositionBottom:
+		return fmt.Sprintf("h*%.3f-%.1f", 1-style.SafeBottom, blockHeight)
+	default:
+		// По центру безопасной области, а не всего кадра
+		return fmt.Sprintf("h*%.3f+(h*%.3f-%.1f)/2", style.SafeTop, 1-style.SafeTop-style.SafeBottom, blockHeight)
+	}
+}
+
+// drawTextFilter собирает фильтр drawtext для одной строки надписи.
+func drawTextFilter(textFile, fontFile, y string, overlay TextOverlay, style OverlayStyle) string {
+	start, end := overlay.Start, overlay.End
+	opts := []string{}
+	if fontFile != "" {
+		opts = append(opts, "fontfile="+escapeFilterValue(fontFile))
+	}
+	opts = append(opts,
+		"textfile="+escapeFilterValue(textFile),
+		"fontcolor="+style.FontColor,
+		fmt.Sprintf("borderw=%d", max(2, overlay.FontSize/16)),
+		"bordercolor="+style.BorderColor,
+		"x=(w-text_w)/2",
+		"y="+escapeFilterValue(y),
+		"enable="+escapeFilterValue(fmt.Sprintf("between(t,%.3f,%.3f)", start, end)),
+	)
+
+	switch overlay.Animation {
+	case AnimationFade:
+		opts = append(opts, "alpha="+escapeFilterValue(fmt.Sprintf(
+			"if(lt(t,%[1]f+%[3]f),(t-%[1]f)/%[3]f,if(gt(t,%[2]f-%[3]f),(%[2]f-t)/%[3]f,1))",
+			start, end, animationSeconds)))
+		opts = append(opts, fmt.Sprintf("fontsize=%d", overlay.FontSize))
+	case AnimationPop:
+		// Шрифт быстро вырастает от 60% до полного размера
+		opts = append(opts, "fontsize="+escapeFilterValue(fmt.Sprintf(
+			"%d*min(1,0.6+0.4*(t-%f)/%f)", overlay.FontSize, start, animationSeconds)))
+	default:
+		opts = append(opts, fmt.Sprintf("fontsize=%d", overlay.FontSize))
+	}
+
+	return "drawtext=" + strings.Join(opts, ":")
+}
+
+// wrapText разбивает текст на строки не длиннее maxChars символов по границам слов.
+func wrapText(text string, maxChars int) []string {
+	words := strings.Fields(text)
+	if maxChars <= 0 || len(words) == 0 {
+		return []string{strings.TrimSpace(text)}
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range words {
+		if current == "" {
+			current = word
+			continue
+		}
+		if utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > maxChars {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current += " " + word
+	}
+	return append(lines, current)
+}
@@ -0,0 +1,105 @@
+// internal/video/progress.go
+package video
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Progress описывает текущее состояние выполнения команды FFmpeg.
+type Progress struct {
+	Task    string  // Краткое название операции
+	OutTime float64 // Сколько секунд результата уже обработано
+	Total   float64 // Ожидаемая длительность результата; 0 — неизвестна
+	Speed   float64 // Скорость обработки относительно реального времени
+	Done    bool
+}
+
+// Percent возвращает процент выполнения или -1, если ожидаемая длительность неизвестна.
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+	return min(100, p.OutTime/p.Total*100)
+}
+
+// ProgressFunc получает обновления прогресса FFmpeg (например, для веб-интерфейса).
+type ProgressFunc func(Progress)
+
+const progressLogInterval = 5 * time.Second
+
+// readProgress разбирает вывод "-progress pipe:1" (пары key=value, блок завершается строкой progress=...),
+// передает обновления в OnProgress и периодически пишет их в лог.
+func (ve *VideoEditor) readProgress(r io.Reader, task string, total float64) {
+	progress := Progress{Task: task, Total: total}
+	lastLog := time.Now()
+	lastLoggedStep := -1
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "out_time_us", "out_time_ms": // out_time_ms исторически тоже в микросекундах
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				progress.OutTime = float64(us) / 1e6
+			}
+		case "out_time":
+			if seconds, ok := parseClock(value); ok {
+				progress.OutTime = seconds
+			}
+		case "speed":
+			if speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "x"), 64); err == nil {
+				progress.Speed = speed
+			}
+		case "progress":
+			progress.Done = value == "end"
+			if ve.OnProgress != nil {
+				ve.OnProgress(progress)
+			}
+
+			// Пишем в лог каждые 10% или, если процент неизвестен, не чаще раза в progressLogInterval
+			percent := progress.Percent()
+			step := int(percent / 10)
+			if progress.Done || (percent >= 0 && step > lastLoggedStep) || (percent < 0 && time.Since(lastLog) >= progressLogInterval) {
+				ve.logProgress(progress)
+				lastLoggedStep = step
+				lastLog = time.Now()
+			}
+		}
+	}
+	// Дочитываем остаток, чтобы FFmpeg не заблокировался на записи в канал
+	_, _ = io.Copy(io.Discard, r)
+}
+
+// logProgress пишет состояние прогресса в лог.
+func (ve *VideoEditor) logProgress(p Progress) {
+	if percent := p.Percent(); percent >= 0 {
+		ve.Logger.Info("FFmpeg (%s): %.0f%% (%.1f из %.1f с, скорость %.2fx)", p.Task, percent, p.OutTime, p.Total, p.Speed)
+		return
+	}
+	ve.Logger.Info("FFmpeg (%s): обработано %.1f с, скорость %.2fx", p.Task, p.OutTime, p.Speed)
+}
+
+// parseClock разбирает время в формате HH:MM:SS.micro.
+func parseClock(value string) (float64, bool) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	var seconds float64
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, false
+		}
+		seconds = seconds*60 + v
+	}
+	return seconds, seconds >= 0
+}
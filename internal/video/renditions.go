@@ -0,0 +1,104 @@
+// internal/video/renditions.go
+package video
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RenditionSpec описывает параметры кодирования одного варианта ролика.
+type RenditionSpec struct {
+	Name         string
+	Width        int
+	Height       int
+	Fit          string // crop или pad
+	VideoCodec   string
+	Profile      string
+	Preset       string
+	VideoBitrate string // Если пусто, используется CRF
+	CRF          int
+	AudioBitrate string
+}
+
+// RenderRenditions за один запуск FFmpeg кодирует входное видео во все указанные варианты,
+// разделяя видеопоток фильтром split. Файлы сохраняются в outputDir как <baseName>_<name>.<format>.
+// Возвращает соответствие имени варианта и пути к файлу.
+func (ve *VideoEditor) RenderRenditions(inputPath, outputDir, baseName, format string, specs []RenditionSpec) (map[string]string, error) {
+	if len(specs) == 0 {
+		return map[string]string{}, nil
+	}
+	ve.Logger.Info("Кодирование %d вариантов ролика из %s", len(specs), inputPath)
+
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("не удалось создать директорию для вариантов %s: %w", outputDir, err)
+	}
+
+	var filter strings.Builder
+	fmt.Fprintf(&filter, "[0:v]split=%d", len(specs))
+	for i := range specs {
+		fmt.Fprintf(&filter, "[s%d]", i)
+	}
+	for i, spec := range specs {
+		filter.WriteString(";")
+		fmt.Fprintf(&filter, "[s%d]%s,setsar=1[o%d]", i, fitFilter(spec.Width, spec.Height, spec.Fit), i)
+	}
+
+	withAudio := ve.hasAudio(inputPath)
+	cmdArgs := []string{"-y", "-i", inputPath, "-filter_complex", filter.String()}
+	outputs := make(map[string]string, len(specs))
+	for i, spec := range specs {
+		outputPath := filepath.Join(outputDir, fmt.Sprintf("%s_%s.%s", baseName, spec.Name, format))
+		outputs[spec.Name] = outputPath
+
+		cmdArgs = append(cmdArgs, "-map", fmt.Sprintf("[o%d]", i))
+		cmdArgs = append(cmdArgs, renditionCodecArgs(spec)...)
+		if withAudio {
+			cmdArgs = append(cmdArgs, "-map", "0:a", "-c:a", "aac")
+			if spec.AudioBitrate != "" {
+				cmdArgs = append(cmdArgs, "-b:a", spec.AudioBitrate)
+			}
+		}
+		cmdArgs = append(cmdArgs, "-movflags", "+faststart", outputPath)
+	}
+
+	if err := ve.runFFmpeg("варианты кодирования", ve.durationOrZero(inputPath), cmdArgs...); err != nil {
+		return nil, err
+	}
+
+	for name, path := range outputs {
+		ve.Logger.Info("Вариант '%s' сохранен: %s", name, path)
+	}
+	return outputs, nil
+}
+
+// fitFilter приводит кадр к нужному размеру: crop заполняет кадр с обрезкой краев,
+// pad вписывает изображение целиком и добавляет черные поля.
+func fitFilter(width, height int, fit string) string {
+	if fit == "pad" {
+		return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2", width, height, width, height)
+	}
+	return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d", width, height, width, height)
+}
+
+// renditionCodecArgs возвращает параметры видеокодека для одного выхода.
+func renditionCodecArgs(spec RenditionSpec) []string {
+	codec := spec.VideoCodec
+	if codec == "" {
+		codec = "libx264"
+	}
+	args := []string{"-c:v", codec, "-pix_fmt", "yuv420p"}
+	if spec.Profile != "" {
+		args = append(args, "-profile:v", spec.Profile)
+	}
+	if spec.Preset != "" {
+		args = append(args, "-preset", spec.Preset)
+	}
+	if spec.VideoBitrate != "" {
+		args = append(args, "-b:v", spec.VideoBitrate, "-maxrate", spec.VideoBitrate, "-bufsize", spec.VideoBitrate)
+	} else if spec.CRF > 0 {
+		args = append(args, "-crf", fmt.Sprintf("%d", spec.CRF))
+	}
+	return args
+}
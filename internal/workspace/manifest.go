@@ -0,0 +1,379 @@
+// internal/workspace/manifest.go
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"ai-content-gen/internal/ai"
+	"ai-content-gen/internal/video"
+)
+
+// Manifest — машиночитаемый журнал запуска: входные данные, все запросы к моделям,
+// параметры сегментов, команды FFmpeg, результаты публикации и созданные файлы.
+// По нему запуск можно проверить, воспроизвести и проанализировать.
+type Manifest struct {
+	RunID      string         `json:"run_id"`
+	Topic      string         `json:"topic"`
+	Channel    string         `json:"channel,omitempty"`
+	Idea       string         `json:"idea,omitempty"`
+	Models     ManifestModels `json:"models"`
+	Status     string         `json:"status"` // running, completed или failed
+	Error      string         `json:"error,omitempty"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+
+	Stages    []StageRecord   `json:"stages"`
+	Script    *ai.Script      `json:"script,omitempty"`
+	Prompts   []ScenePrompt   `json:"prompts,omitempty"`
+	TextCalls []ai.TextCall   `json:"text_calls"`
+	Segments  []SegmentRecord `json:"segments"`
+	FFmpeg    []CommandRecord `json:"ffmpeg"`
+	Uploads   []UploadRecord  `json:"uploads"`
+	Artifacts []Artifact      `json:"artifacts"`
+
+	Metadata []PublishMetadata `json:"metadata,omitempty"`
+	Review   *ReviewRecord     `json:"review,omitempty"`
+
+	mu sync.Mutex
+}
+
+// ManifestModels перечисляет модели и бэкенды, участвовавшие в запуске.
+type ManifestModels struct {
+	Text          string   `json:"text"`
+	VideoBackends []string `json:"video_backends"`
+	Image         string   `json:"image,omitempty"`
+}
+
+// SegmentRecord — сгенерированный сегмент с контрольной суммой файла.
+type SegmentRecord struct {
+	ai.VideoSegment
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// CommandRecord — запуск FFmpeg.
+type CommandRecord struct {
+	Task      string        `json:"task"`
+	Args      []string      `json:"args"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// StageRecord — ход одного этапа запуска.
+type StageRecord struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"` // running, completed или failed
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// ScenePrompt — сцена сценария и подробный промпт для ее генерации.
+// Номер сегмента (VideoSegment.Index) соответствует позиции в Manifest.Prompts, начиная с 1.
+type ScenePrompt struct {
+	Scene  ai.Scene `json:"scene"`
+	Prompt string   `json:"prompt"`
+}
+
+// UploadRecord — результат публикации на одной платформе.
+type UploadRecord struct {
+	Platform   string    `json:"platform"`
+	Path       string    `json:"path"`
+	URL        string    `json:"url,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	UploadedAt time.Time `json:"uploaded_at"`
+	// PublishAt — время выхода ролика, если публикация отложена
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// Queued — ролик ждет в локальной очереди публикаций (платформа не умеет откладывать публикацию сама)
+	Queued bool `json:"queued,omitempty"`
+}
+
+// PublishMetadata — заголовок, описание и теги ролика для одной платформы. Проверяющий может их изменить до публикации.
+type PublishMetadata struct {
+	Platform    string `json:"platform"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Tags        string `json:"tags"`
+}
+
+// ReviewRecord — проверка ролика человеком перед публикацией.
+type ReviewRecord struct {
+	Status    string    `json:"status"` // awaiting_review, approved или rejected
+	Reviewer  string    `json:"reviewer,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Статусы запуска и этапов в манифесте.
+const (
+	StatusRunning        = "running"
+	StatusCompleted      = "completed"
+	StatusFailed         = "failed"
+	StatusAwaitingReview = "awaiting_review" // Ролик готов и ждет решения проверяющего
+)
+
+// Решения проверяющего.
+const (
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// Start заполняет входные данные запуска.
+func (m *Manifest) Start(topic, channel string, models ManifestModels) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Topic, m.Channel, m.Models, m.Status = topic, channel, models, StatusRunning
+}
+
+// SetIdea сохраняет идею ролика, извлеченную из сценария.
+func (m *Manifest) SetIdea(idea string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Idea = idea
+}
+
+// Finish отмечает завершение запуска; err == nil означает успех.
+func (m *Manifest) Finish(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.FinishedAt = &now
+	m.Status = StatusCompleted
+	if err != nil {
+		m.Status, m.Error = StatusFailed, err.Error()
+	}
+}
+
+// StartStage отмечает начало этапа. Повторно запущенный этап записывается заново.
+func (m *Manifest) StartStage(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Stages = append(m.Stages, StageRecord{Name: name, Status: StatusRunning, StartedAt: time.Now()})
+}
+
+// FinishStage отмечает завершение последней попытки этапа; err == nil означает успех.
+func (m *Manifest) FinishStage(name string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.Stages) - 1; i >= 0; i-- {
+		if m.Stages[i].Name != name {
+			continue
+		}
+		now := time.Now()
+		m.Stages[i].FinishedAt = &now
+		m.Stages[i].Status = StatusCompleted
+		if err != nil {
+			m.Stages[i].Status, m.Stages[i].Error = StatusFailed, err.Error()
+		}
+		return
+	}
+}
+
+// PauseStage отмечает, что этап остановлен до решения проверяющего. Повтор запуска начнется с этого этапа.
+func (m *Manifest) PauseStage(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.Stages) - 1; i >= 0; i-- {
+		if m.Stages[i].Name == name {
+			now := time.Now()
+			m.Stages[i].FinishedAt, m.Stages[i].Status = &now, StatusAwaitingReview
+			return
+		}
+	}
+}
+
+// LastStage возвращает последнюю запись этапа (копию) или nil, если этап не запускался.
+func (m *Manifest) LastStage(name string) *StageRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.Stages) - 1; i >= 0; i-- {
+		if m.Stages[i].Name == name {
+			stage := m.Stages[i]
+			return &stage
+		}
+	}
+	return nil
+}
+
+// SetScript сохраняет разобранный сценарий с распределенными длительностями сцен.
+func (m *Manifest) SetScript(script *ai.Script) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Script = script
+}
+
+// SetPrompts сохраняет промпты сцен.
+func (m *Manifest) SetPrompts(prompts []ScenePrompt) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Prompts = prompts
+}
+
+// ResetSegments забывает сгенерированные сегменты перед их повторной генерацией.
+func (m *Manifest) ResetSegments() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Segments = nil
+}
+
+// Resume возвращает завершенный запуск в состояние running перед повтором этапов.
+func (m *Manifest) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Status, m.Error, m.FinishedAt = StatusRunning, "", nil
+}
+
+// AwaitReview отмечает, что запуск остановлен до решения проверяющего.
+func (m *Manifest) AwaitReview() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.Status, m.Error, m.FinishedAt = StatusAwaitingReview, "", &now
+	m.Review = &ReviewRecord{Status: StatusAwaitingReview, UpdatedAt: now}
+}
+
+// SetReview сохраняет решение проверяющего (ReviewApproved или ReviewRejected).
+func (m *Manifest) SetReview(status, reviewer, comment string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Review = &ReviewRecord{Status: status, Reviewer: reviewer, Comment: comment, UpdatedAt: time.Now()}
+}
+
+// ReviewDecision возвращает решение проверяющего или nil, если ролик еще не проверяли.
+func (m *Manifest) ReviewDecision() *ReviewRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Review == nil || m.Review.Status == StatusAwaitingReview {
+		return nil
+	}
+	review := *m.Review
+	return &review
+}
+
+// ResetReview забывает прошлую проверку: ролик собран заново и должен быть проверен снова.
+func (m *Manifest) ResetReview() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Review = nil
+}
+
+// PublishMetadata возвращает метаданные ролика для платформы.
+func (m *Manifest) PublishMetadata(platform string) (PublishMetadata, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, metadata := range m.Metadata {
+		if metadata.Platform == platform {
+			return metadata, true
+		}
+	}
+	return PublishMetadata{}, false
+}
+
+// ResetPublishMetadata забывает метаданные ролика: они описывали прежнюю идею.
+func (m *Manifest) ResetPublishMetadata() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Metadata = nil
+}
+
+// SetPublishMetadata сохраняет метаданные ролика для платформы, заменяя прежние.
+func (m *Manifest) SetPublishMetadata(metadata PublishMetadata) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.Metadata {
+		if m.Metadata[i].Platform == metadata.Platform {
+			m.Metadata[i] = metadata
+			return
+		}
+	}
+	m.Metadata = append(m.Metadata, metadata)
+}
+
+// RecordTextCall добавляет запрос к текстовой модели. Подходит как ai.TextCallFunc.
+func (m *Manifest) RecordTextCall(call ai.TextCall) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.TextCalls = append(m.TextCalls, call)
+}
+
+// RecordSegment добавляет сгенерированный сегмент и контрольную сумму его файла.
+func (m *Manifest) RecordSegment(segment *ai.VideoSegment) {
+	record := SegmentRecord{VideoSegment: *segment, SHA256: fileSHA256(segment.Path)}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Segments = append(m.Segments, record)
+}
+
+// RecordCommand добавляет запуск FFmpeg. Подходит как video.CommandFunc.
+func (m *Manifest) RecordCommand(cmd video.Command) {
+	record := CommandRecord{Task: cmd.Task, Args: cmd.Args, StartedAt: cmd.StartedAt, Duration: cmd.Duration}
+	if cmd.Err != nil {
+		record.Error = cmd.Err.Error()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.FFmpeg = append(m.FFmpeg, record)
+}
+
+// RecordUpload добавляет результат публикации на платформе.
+func (m *Manifest) RecordUpload(platform, path, url string, err error) {
+	record := UploadRecord{Platform: platform, Path: path, URL: url, UploadedAt: time.Now()}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Uploads = append(m.Uploads, record)
+}
+
+// RecordScheduledUpload добавляет отложенную публикацию: загруженную с отложенным выходом
+// или поставленную в локальную очередь (queued).
+func (m *Manifest) RecordScheduledUpload(platform, path, url string, publishAt time.Time, queued bool, err error) {
+	record := UploadRecord{Platform: platform, Path: path, URL: url, UploadedAt: time.Now(), PublishAt: &publishAt, Queued: queued}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Uploads = append(m.Uploads, record)
+}
+
+func (m *Manifest) addArtifact(artifact Artifact) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Artifacts = append(m.Artifacts, artifact)
+}
+
+// marshal сериализует манифест под блокировкой; update может дополнить копии артефактов.
+func (m *Manifest) marshal(update func(*Artifact)) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	artifacts := append([]Artifact(nil), m.Artifacts...)
+	for i := range artifacts {
+		update(&artifacts[i])
+	}
+	original := m.Artifacts
+	m.Artifacts = artifacts
+	defer func() { m.Artifacts = original }()
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// fileSHA256 возвращает SHA-256 содержимого файла или пустую строку, если файл недоступен.
+func fileSHA256(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,223 @@
+// internal/workspace/workspace.go
+package workspace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"ai-content-gen/internal/config"
+	"ai-content-gen/pkg/utils"
+)
+
+const (
+	defaultRoot          = "runs"
+	defaultMaxNameLength = 60
+	manifestFile         = "manifest.json"
+)
+
+// Виды артефактов в манифесте.
+const (
+	KindSegment   = "segment"
+	KindVideo     = "video"
+	KindRendition = "rendition"
+	KindThumbnail = "thumbnail"
+	KindParams    = "params"
+)
+
+// Artifact — файл, созданный во время запуска.
+type Artifact struct {
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name,omitempty"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	Removed   bool      `json:"removed,omitempty"` // Промежуточный файл удален после завершения запуска
+}
+
+// Workspace — изолированная рабочая директория одного запуска: <root>/<run id>/ с подкаталогами
+// tmp (промежуточные файлы) и output (результаты). Параллельные запуски не пересекаются по путям.
+type Workspace struct {
+	RunID     string
+	Dir       string
+	TempDir   string
+	OutputDir string
+	Manifest  *Manifest // Журнал запуска, сохраняется в <run>/manifest.json
+	Config    config.WorkspaceConfig
+	Logger    *utils.Logger
+}
+
+// New создает рабочую директорию для нового запуска под cfg.Root.
+func New(cfg config.WorkspaceConfig, logger *utils.Logger) (*Workspace, error) {
+	runID, err := newRunID()
+	if err != nil {
+		return nil, err
+	}
+	return Open(cfg, runID, logger)
+}
+
+// Open открывает (и при необходимости создает) рабочую директорию запуска с известным run id.
+func Open(cfg config.WorkspaceConfig, runID string, logger *utils.Logger) (*Workspace, error) {
+	if cfg.Root == "" {
+		cfg.Root = defaultRoot
+	}
+	if cfg.MaxNameLength <= 0 {
+		cfg.MaxNameLength = defaultMaxNameLength
+	}
+	dir := filepath.Join(cfg.Root, runID)
+	ws := &Workspace{
+		RunID:     runID,
+		Dir:       dir,
+		TempDir:   filepath.Join(dir, "tmp"),
+		OutputDir: filepath.Join(dir, "output"),
+		Manifest:  &Manifest{RunID: runID, StartedAt: time.Now()},
+		Config:    cfg,
+		Logger:    logger,
+	}
+	for _, d := range []string{ws.TempDir, ws.OutputDir} {
+		if err := os.MkdirAll(d, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("не удалось создать рабочую директорию %s: %w", d, err)
+		}
+	}
+	return ws, nil
+}
+
+// newRunID создает сортируемый по времени идентификатор запуска со случайным суффиксом.
+func newRunID() (string, error) {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("не удалось создать идентификатор запуска: %w", err)
+	}
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix), nil
+}
+
+// Temp возвращает путь к промежуточному файлу запуска.
+func (w *Workspace) Temp(name string) string {
+	return filepath.Join(w.TempDir, name)
+}
+
+// Output возвращает путь к файлу результата запуска.
+func (w *Workspace) Output(name string) string {
+	return filepath.Join(w.OutputDir, name)
+}
+
+// FileName строит безопасное имя файла результата из произвольного текста (например, идеи ролика).
+func (w *Workspace) FileName(text, suffix, ext string) string {
+	name := SafeName(text, w.Config.MaxNameLength)
+	if suffix != "" {
+		name += "_" + suffix
+	}
+	return name + "." + strings.TrimPrefix(ext, ".")
+}
+
+// Add регистрирует артефакт в манифесте. Размер берется с диска на момент вызова.
+func (w *Workspace) Add(kind, name, path string) {
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	w.Manifest.addArtifact(Artifact{
+		Kind:      kind,
+		Name:      name,
+		Path:      path,
+		Size:      size,
+		CreatedAt: time.Now(),
+	})
+}
+
+// WriteManifest сохраняет манифест запуска в <run>/manifest.json.
+// Артефакты, удаленные к этому моменту (например, сегменты после Cleanup), помечаются как removed.
+// Манифест можно сохранять многократно по ходу запуска.
+func (w *Workspace) WriteManifest() (string, error) {
+	data, err := w.Manifest.marshal(func(artifact *Artifact) {
+		if _, err := os.Stat(artifact.Path); err != nil {
+			artifact.Removed = true
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("ошибка сериализации манифеста запуска: %w", err)
+	}
+	path := ManifestPath(w.Dir)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("не удалось записать манифест запуска: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", fmt.Errorf("не удалось записать манифест запуска: %w", err)
+	}
+	return path, nil
+}
+
+// LoadManifest читает сохраненный манифест запуска, чтобы дополнить его (например, отложенной публикацией).
+func (w *Workspace) LoadManifest() error {
+	manifest, err := ReadManifest(w.Dir)
+	if err != nil {
+		return err
+	}
+	w.Manifest = manifest
+	return nil
+}
+
+// ManifestPath возвращает путь к манифесту запуска в директории dir.
+func ManifestPath(dir string) string {
+	return filepath.Join(dir, manifestFile)
+}
+
+// ReadManifest читает манифест запуска из директории dir, не открывая рабочую директорию.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(ManifestPath(dir))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать манифест запуска %s: %w", filepath.Base(dir), err)
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("ошибка разбора манифеста запуска %s: %w", filepath.Base(dir), err)
+	}
+	return manifest, nil
+}
+
+// Cleanup удаляет промежуточные файлы запуска, если не включено workspace.keep_temp.
+func (w *Workspace) Cleanup() {
+	if w.Config.KeepTemp {
+		w.Logger.Info("Промежуточные файлы сохранены: %s", w.TempDir)
+		return
+	}
+	if err := os.RemoveAll(w.TempDir); err != nil {
+		w.Logger.Warn("Не удалось удалить временную папку %s: %v", w.TempDir, err)
+	}
+}
+
+// SafeName превращает произвольный текст в имя файла: буквы и цифры сохраняются,
+// остальные символы заменяются подчеркиванием, длина ограничивается maxLength символами.
+func SafeName(text string, maxLength int) string {
+	var b strings.Builder
+	underscore := false
+	count := 0
+	for _, r := range strings.TrimSpace(text) {
+		if maxLength > 0 && count >= maxLength {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			b.WriteRune(r)
+			underscore = false
+			count++
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteRune('_')
+			underscore = true
+			count++
+		}
+	}
+	name := strings.Trim(b.String(), "_-")
+	if name == "" {
+		return "short"
+	}
+	return name
+}